@@ -0,0 +1,175 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="Objects" tilewidth="74" tileheight="136" tilecount="55" columns="0">
+ <grid orientation="orthogonal" width="1" height="1"/>
+ <tile id="0">
+  <image width="9" height="4" source="../world/3 Objects/Grass/1.png"/>
+ </tile>
+ <tile id="1">
+  <image width="12" height="7" source="../world/3 Objects/Grass/2.png"/>
+ </tile>
+ <tile id="2">
+  <image width="12" height="7" source="../world/3 Objects/Grass/3.png"/>
+ </tile>
+ <tile id="3">
+  <image width="20" height="11" source="../world/3 Objects/Grass/4.png"/>
+ </tile>
+ <tile id="4">
+  <image width="19" height="10" source="../world/3 Objects/Grass/5.png"/>
+ </tile>
+ <tile id="5">
+  <image width="23" height="11" source="../world/3 Objects/Grass/6.png"/>
+ </tile>
+ <tile id="6">
+  <image width="29" height="14" source="../world/3 Objects/Grass/7.png"/>
+ </tile>
+ <tile id="7">
+  <image width="30" height="13" source="../world/3 Objects/Grass/8.png"/>
+ </tile>
+ <tile id="8">
+  <image width="9" height="5" source="../world/3 Objects/Grass/9.png"/>
+ </tile>
+ <tile id="9">
+  <image width="11" height="7" source="../world/3 Objects/Grass/10.png"/>
+ </tile>
+ <tile id="10">
+  <image width="12" height="6" source="../world/3 Objects/Grass/11.png"/>
+ </tile>
+ <tile id="11">
+  <image width="14" height="7" source="../world/3 Objects/Grass/12.png"/>
+ </tile>
+ <tile id="12">
+  <image width="19" height="8" source="../world/3 Objects/Grass/13.png"/>
+ </tile>
+ <tile id="13">
+  <image width="23" height="11" source="../world/3 Objects/Grass/14.png"/>
+ </tile>
+ <tile id="14">
+  <image width="30" height="14" source="../world/3 Objects/Grass/15.png"/>
+ </tile>
+ <tile id="15">
+  <image width="31" height="18" source="../world/3 Objects/Grass/16.png"/>
+ </tile>
+ <tile id="16">
+  <image width="7" height="3" source="../world/3 Objects/Grass/17.png"/>
+ </tile>
+ <tile id="17">
+  <image width="9" height="4" source="../world/3 Objects/Grass/18.png"/>
+ </tile>
+ <tile id="18">
+  <image width="11" height="7" source="../world/3 Objects/Grass/19.png"/>
+ </tile>
+ <tile id="19">
+  <image width="13" height="9" source="../world/3 Objects/Grass/20.png"/>
+ </tile>
+ <tile id="20">
+  <image width="14" height="9" source="../world/3 Objects/Grass/21.png"/>
+ </tile>
+ <tile id="21">
+  <image width="16" height="11" source="../world/3 Objects/Grass/22.png"/>
+ </tile>
+ <tile id="22">
+  <image width="15" height="12" source="../world/3 Objects/Grass/23.png"/>
+ </tile>
+ <tile id="23">
+  <image width="14" height="10" source="../world/3 Objects/Grass/24.png"/>
+ </tile>
+ <tile id="24">
+  <image width="30" height="24" source="../world/3 Objects/Other/Box1.png"/>
+ </tile>
+ <tile id="25">
+  <image width="21" height="16" source="../world/3 Objects/Other/Box2.png"/>
+ </tile>
+ <tile id="26">
+  <image width="30" height="24" source="../world/3 Objects/Other/Box3.png"/>
+ </tile>
+ <tile id="27">
+  <image width="21" height="16" source="../world/3 Objects/Other/Box4.png"/>
+ </tile>
+ <tile id="28">
+  <properties>
+   <property name="checkpoint" type="bool" value="true"/>
+  </properties>
+  <image width="14" height="42" source="../world/3 Objects/Other/Pointer1.png"/>
+ </tile>
+ <tile id="29">
+  <properties>
+   <property name="checkpoint" type="bool" value="true"/>
+  </properties>
+  <image width="14" height="41" source="../world/3 Objects/Other/Pointer2.png"/>
+ </tile>
+ <tile id="30">
+  <image width="4" height="10" source="../world/3 Objects/Other/Pointer3.png"/>
+ </tile>
+ <tile id="31">
+  <image width="23" height="10" source="../world/3 Objects/Stones/1.png"/>
+ </tile>
+ <tile id="32">
+  <image width="35" height="16" source="../world/3 Objects/Stones/2.png"/>
+ </tile>
+ <tile id="33">
+  <image width="37" height="17" source="../world/3 Objects/Stones/3.png"/>
+ </tile>
+ <tile id="34">
+  <image width="41" height="25" source="../world/3 Objects/Stones/4.png"/>
+ </tile>
+ <tile id="35">
+  <image width="66" height="27" source="../world/3 Objects/Stones/5.png"/>
+ </tile>
+ <tile id="36">
+  <image width="74" height="31" source="../world/3 Objects/Stones/6.png"/>
+ </tile>
+ <tile id="37">
+  <image width="62" height="136" source="../world/3 Objects/Trees/1.png"/>
+ </tile>
+ <tile id="38">
+  <image width="68" height="136" source="../world/3 Objects/Trees/2.png"/>
+ </tile>
+ <tile id="39">
+  <image width="67" height="100" source="../world/3 Objects/Trees/3.png"/>
+ </tile>
+ <tile id="40">
+  <image width="49" height="88" source="../world/3 Objects/Trees/4.png"/>
+ </tile>
+ <tile id="41">
+  <image width="63" height="95" source="../world/3 Objects/Trees/5.png"/>
+ </tile>
+ <tile id="42">
+  <image width="65" height="107" source="../world/3 Objects/Trees/6.png"/>
+ </tile>
+ <tile id="43">
+  <image width="43" height="85" source="../world/3 Objects/Trees/7.png"/>
+ </tile>
+ <tile id="44">
+  <image width="47" height="91" source="../world/3 Objects/Trees/8.png"/>
+ </tile>
+ <tile id="45">
+  <image width="68" height="113" source="../world/3 Objects/Trees/9.png"/>
+ </tile>
+ <tile id="46">
+  <image width="52" height="101" source="../world/3 Objects/Trees/10.png"/>
+ </tile>
+ <tile id="47">
+  <image width="62" height="104" source="../world/3 Objects/Trees/11.png"/>
+ </tile>
+ <tile id="48">
+  <image width="45" height="79" source="../world/3 Objects/Trees/12.png"/>
+ </tile>
+ <tile id="49">
+  <image width="32" height="24" source="../world/3 Objects/Trees/13.png"/>
+ </tile>
+ <tile id="50">
+  <image width="16" height="108" source="../world/3 Objects/Trees/14.png"/>
+ </tile>
+ <tile id="51">
+  <image width="15" height="119" source="../world/3 Objects/Trees/15.png"/>
+ </tile>
+ <tile id="52">
+  <image width="21" height="54" source="../world/3 Objects/Trees/16.png"/>
+ </tile>
+ <tile id="53">
+  <image width="38" height="66" source="../world/3 Objects/Trees/17.png"/>
+ </tile>
+ <tile id="54">
+  <image width="64" height="98" source="../world/3 Objects/Trees/18.png"/>
+ </tile>
+</tileset>
@@ -0,0 +1,73 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="Tileset" tilewidth="32" tileheight="32" tilecount="81" columns="9">
+ <image source="../world/1 Tiles/Tileset.png" width="288" height="288"/>
+ <tile id="4">
+  <properties>
+   <property name="shape" value="none"/>
+  </properties>
+ </tile>
+ <tile id="5">
+  <properties>
+   <property name="shape" value="none"/>
+  </properties>
+ </tile>
+ <tile id="6">
+  <properties>
+   <property name="shape" value="none"/>
+  </properties>
+ </tile>
+ <tile id="7">
+  <properties>
+   <property name="shape" value="none"/>
+  </properties>
+ </tile>
+ <tile id="32">
+  <properties>
+   <property name="shape" value="slope_right"/>
+  </properties>
+ </tile>
+ <tile id="33">
+  <properties>
+   <property name="shape" value="slope_left"/>
+  </properties>
+ </tile>
+ <tile id="34">
+  <properties>
+   <property name="shape" value="none"/>
+  </properties>
+ </tile>
+ <tile id="52">
+  <properties>
+   <property name="shape" value="oneway"/>
+  </properties>
+ </tile>
+ <tile id="53">
+  <properties>
+   <property name="shape" value="oneway"/>
+  </properties>
+ </tile>
+ <wangsets>
+  <wangset name="Ground" type="edge" tile="10">
+   <wangcolor name="ground" color="#5a6e8c" tile="10" probability="1"/>
+   <wangtile tileid="0" wangid="0,0,1,0,1,0,0,0"/>
+   <wangtile tileid="1" wangid="0,0,1,0,1,0,1,0"/>
+   <wangtile tileid="2" wangid="0,0,1,0,1,0,1,0"/>
+   <wangtile tileid="3" wangid="0,0,0,0,1,0,1,0"/>
+   <wangtile tileid="8" wangid="0,0,0,0,1,0,0,0"/>
+   <wangtile tileid="9" wangid="1,0,1,0,1,0,0,0"/>
+   <wangtile tileid="10" wangid="1,0,1,0,1,0,1,0"/>
+   <wangtile tileid="11" wangid="1,0,1,0,1,0,1,0"/>
+   <wangtile tileid="12" wangid="1,0,0,0,1,0,1,0"/>
+   <wangtile tileid="17" wangid="1,0,0,0,1,0,0,0"/>
+   <wangtile tileid="18" wangid="1,0,1,0,0,0,0,0"/>
+   <wangtile tileid="19" wangid="1,0,1,0,0,0,1,0"/>
+   <wangtile tileid="20" wangid="1,0,1,0,0,0,1,0"/>
+   <wangtile tileid="21" wangid="1,0,0,0,0,0,1,0"/>
+   <wangtile tileid="22" wangid="0,0,1,0,0,0,0,0"/>
+   <wangtile tileid="23" wangid="0,0,1,0,0,0,1,0"/>
+   <wangtile tileid="24" wangid="0,0,1,0,0,0,1,0"/>
+   <wangtile tileid="25" wangid="0,0,0,0,0,0,1,0"/>
+   <wangtile tileid="26" wangid="1,0,0,0,0,0,0,0"/>
+  </wangset>
+ </wangsets>
+</tileset>
@@ -0,0 +1,126 @@
+package assets
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"log"
+	"os"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TextureHandle is one reference to a shared texture, release it once you are done with it
+type TextureHandle struct {
+	Path     string
+	Texture  rl.Texture2D
+	manager  *Manager
+	released bool
+}
+
+type textureEntry struct {
+	texture rl.Texture2D
+	refs    int
+}
+
+// Manager loads each texture path once and shares it between everyone asking for it,
+// freeing the texture when the last handle to it is released
+type Manager struct {
+	textures map[string]*textureEntry
+}
+
+// Default is the manager the game loads all of its textures through
+var Default = NewManager()
+
+// NewManager creates an empty asset manager
+func NewManager() *Manager {
+	return &Manager{textures: map[string]*textureEntry{}}
+}
+
+// LoadTexture returns a handle to the texture at path, loading it on first use
+func (m *Manager) LoadTexture(path string) *TextureHandle {
+	entry, ok := m.textures[path]
+	if !ok {
+		entry = &textureEntry{texture: loadTexture(path)}
+		m.textures[path] = entry
+	}
+	entry.refs++
+
+	return &TextureHandle{
+		Path:    path,
+		Texture: entry.texture,
+		manager: m,
+	}
+}
+
+// Release gives up this handle, unloading the texture when no handles remain.
+// Releasing the same handle twice is a no-op
+func (h *TextureHandle) Release() {
+	if h == nil || h.released {
+		return
+	}
+	h.released = true
+
+	entry, ok := h.manager.textures[h.Path]
+	if !ok {
+		return
+	}
+
+	entry.refs--
+	if entry.refs <= 0 {
+		unloadTexture(entry.texture)
+		delete(h.manager.textures, h.Path)
+	}
+}
+
+// Leaks lists every texture still referenced along with how many handles are outstanding
+func (m *Manager) Leaks() []string {
+	var leaks []string
+	for path, entry := range m.textures {
+		leaks = append(leaks, fmt.Sprintf("%s (%d refs)", path, entry.refs))
+	}
+	sort.Strings(leaks)
+	return leaks
+}
+
+// Shutdown reports any textures that were never released and frees them, call it before closing the window
+func (m *Manager) Shutdown() {
+	for _, leak := range m.Leaks() {
+		log.Printf("asset leak: %s", leak)
+	}
+
+	for path, entry := range m.textures {
+		unloadTexture(entry.texture)
+		delete(m.textures, path)
+	}
+}
+
+// loadTexture loads a texture onto the GPU. Without a window (headless runs, tests)
+// there is no GPU context, so only the texture's size is read from the file to
+// keep frame and collision maths working
+func loadTexture(path string) rl.Texture2D {
+	if rl.IsWindowReady() {
+		return rl.LoadTexture(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return rl.Texture2D{}
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return rl.Texture2D{}
+	}
+
+	return rl.Texture2D{Width: int32(config.Width), Height: int32(config.Height)}
+}
+
+// unloadTexture frees a texture from loadTexture, skipping headless ones
+func unloadTexture(texture rl.Texture2D) {
+	if texture.ID != 0 {
+		rl.UnloadTexture(texture)
+	}
+}
@@ -1,86 +1,79 @@
 package characters
 
 import (
-	"fmt"
+	"github.com/grcatterall/go-game/classes/helpers"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 type Enemy struct {
-	Texture           rl.Texture2D
-	Position          rl.Vector2
-	FrameRec          rl.Rectangle
-	FrameWidth        float32
-	FrameHeight       float32
-	FrameSpeed        float32
-	FrameCounter      float32
-	FramesCount       int32
-	CurrentFrame      int32
-	Health            int32
-	idleTexture       rl.Texture2D
-	walkingTexture    rl.Texture2D
-	attackingTexture  rl.Texture2D
-	Target            *Player
-	speed             float32
-	detectionDistance float32
-	attackRange       float32
-	isMoving          bool
-	isAttacking       bool
+	Position           rl.Vector2
+	PreviousPosition   rl.Vector2
+	Health             int32
+	IdleAnimation      helpers.Animation
+	WalkingAnimation   helpers.Animation
+	AttackingAnimation helpers.Animation
+	CurrentAnimation   *helpers.Animation
+	Animations         *helpers.AnimationStateMachine
+	Target             *Player
+	FacingLeft         bool
+	PatrolRange        float32 // how far either side of its spawn the enemy wanders, 0 to stand still
+	home               float32
+	speed              float32 // pixels per second
+	detectionDistance  float32
+	attackRange        float32
+	isMoving           bool
+	isAttacking        bool
 }
 
+// NewEnemy creates an enemy of the given character, its sprites come from the character's manifest
 func NewEnemy(
 	character string,
 	health int32,
 	position rl.Vector2,
-	frameSpeed float32,
-	spriteHeight int32,
-	spriteWidth int32,
 	target *Player) *Enemy {
 
-	idleTexture := rl.LoadTexture(fmt.Sprintf("assets/characters/%s/Idle.png", character))
-	walkingTexture := rl.LoadTexture(fmt.Sprintf("assets/characters/%s/Walk.png", character))
-	attackingTexture := rl.LoadTexture(fmt.Sprintf("assets/characters/%s/Attack_1.png", character))
-
-	framesCount := idleTexture.Width / spriteWidth // Assuming each frame is 128x128 pixels
-	frameWidth := float32(idleTexture.Width) / float32(framesCount)
-	frameHeight := float32(idleTexture.Height)
-
-	return &Enemy{
-		Texture:  idleTexture,
-		Position: position,
-		FrameRec: rl.Rectangle{
-			X:      0,
-			Y:      0,
-			Width:  frameWidth,
-			Height: frameHeight,
-		},
-		CurrentFrame:      0,
-		FrameWidth:        frameWidth,
-		FrameHeight:       frameHeight,
-		FrameSpeed:        frameSpeed,
-		FramesCount:       framesCount,
-		idleTexture:       idleTexture,
-		walkingTexture:    walkingTexture,
-		attackingTexture:  attackingTexture,
-		Health:            health,
-		Target:            target,
-		speed:             0.5,
-		detectionDistance: 300,
-		attackRange:       30,
+	manifest := loadManifestOrEmpty(character)
+
+	// Enemies of the same character share these textures through the asset manager
+	e := &Enemy{
+		Position:           position,
+		PreviousPosition:   position,
+		IdleAnimation:      manifest.LoadAnimation("idle"),
+		WalkingAnimation:   manifest.loadAnimationOr("walk", "idle"),
+		AttackingAnimation: manifest.loadAnimationOr("attack", "idle"),
+		Health:             health,
+		Target:             target,
+		home:               position.X,
+		speed:              30,
+		detectionDistance:  300,
+		attackRange:        30,
 	}
+	e.Animations = e.newAnimationStateMachine()
+	e.CurrentAnimation = e.Animations.Animation()
+
+	return e
 }
 
-func (e *Enemy) Update() {
-	// Update animation
-	e.FrameCounter += e.FrameSpeed
-	if e.FrameCounter >= 1 {
-		e.FrameCounter = 0
-		e.CurrentFrame++
-		if e.CurrentFrame >= e.FramesCount {
-			e.CurrentFrame = 0
-		}
-		e.FrameRec.X = float32(e.CurrentFrame) * e.FrameWidth
-	}
+// newAnimationStateMachine declares the enemy's animation states, an attack
+// plays through before the enemy goes back to walking or standing
+func (e *Enemy) newAnimationStateMachine() *helpers.AnimationStateMachine {
+	sm := helpers.NewAnimationStateMachine()
+
+	sm.AddState("idle", &e.IdleAnimation, false, 0)
+	sm.AddState("walk", &e.WalkingAnimation, false, 0)
+	sm.AddState("attack", &e.AttackingAnimation, true, 1)
+
+	sm.AddTransition(helpers.AnyState, "attack", 0, func() bool { return e.isAttacking })
+	sm.AddTransition(helpers.AnyState, "walk", 0, func() bool { return e.isMoving })
+	sm.AddTransition(helpers.AnyState, "idle", 0, func() bool { return !e.isMoving && !e.isAttacking })
+
+	return sm
+}
+
+// Update advances the enemy's animation and behaviour by dt seconds
+func (e *Enemy) Update(dt float32) {
+	e.PreviousPosition = e.Position
 
 	distanceToTarget := e.Position.X - e.Target.Position.X
 
@@ -89,51 +82,105 @@ func (e *Enemy) Update() {
 	}
 
 	if distanceToTarget < e.attackRange {
+		e.isMoving = false
 		e.isAttacking = true
 	} else if distanceToTarget <= e.detectionDistance && distanceToTarget > e.attackRange+1 {
 		e.isMoving = true
-		e.moveToTarget()
-	} else {
+		e.isAttacking = false
+		e.moveToTarget(dt)
+	} else if distanceToTarget <= e.detectionDistance {
 		e.isMoving = false
 		e.isAttacking = false
+	} else {
+		// Nobody in sight, so wander instead
+		e.isAttacking = false
+		e.isMoving = e.patrol(dt)
 	}
 
-	if e.isMoving {
-		e.Texture = e.walkingTexture
-	} else if e.isAttacking {
-		e.Texture = e.attackingTexture
-	} else {
-		e.Texture = e.idleTexture
+	if distanceToTarget <= e.detectionDistance {
+		e.FacingLeft = e.Target.Position.X < e.Position.X
 	}
 
-	e.renderTexture(e.Texture)
+	e.Animations.Update(dt)
+	e.CurrentAnimation = e.Animations.Animation()
 }
 
-func (e *Enemy) Draw() {
-	drawPosition := rl.Vector2{X: e.Position.X, Y: e.Position.Y}
-	if e.Target.Position.X > e.Position.X {
-		rl.DrawTextureRec(e.Texture, e.FrameRec, drawPosition, rl.White)
-	} else {
-		rl.DrawTextureRec(e.Texture, rl.Rectangle{X: e.FrameRec.X + e.FrameRec.Width, Y: e.FrameRec.Y, Width: -e.FrameRec.Width, Height: e.FrameRec.Height}, drawPosition, rl.White)
+// Move shifts the enemy and the stretch it patrols, like when a platform carries it
+func (e *Enemy) Move(delta rl.Vector2) {
+	e.Position = rl.Vector2Add(e.Position, delta)
+	e.home += delta.X
+}
+
+// Draw renders the enemy, interpolated alpha of the way between the last two ticks
+func (e *Enemy) Draw(alpha float32) {
+	animation := e.CurrentAnimation
+	origin := animation.Origin()
+	frameRec := animation.FrameRec
 
+	drawPosition := rl.Vector2{
+		X: helpers.Lerp(e.PreviousPosition.X, e.Position.X, alpha) - origin.X,
+		Y: helpers.Lerp(e.PreviousPosition.Y, e.Position.Y, alpha) - origin.Y,
 	}
+	if !e.FacingLeft {
+		rl.DrawTextureRec(animation.Texture, frameRec, drawPosition, rl.White)
+	} else {
+		rl.DrawTextureRec(animation.Texture, rl.Rectangle{X: frameRec.X + frameRec.Width, Y: frameRec.Y, Width: -frameRec.Width, Height: frameRec.Height}, drawPosition, rl.White)
+	}
+}
+
+// Bounds returns the enemy's body in world coordinates, ignoring the empty space
+// around the idle sprite so the hitbox doesn't change size between animations
+func (e *Enemy) Bounds() rl.Rectangle {
+	frameWidth := e.IdleAnimation.FrameRec.Width
+	frameHeight := e.IdleAnimation.FrameRec.Height
+	left := e.Position.X - e.IdleAnimation.Pivot.X
+	top := e.Position.Y - e.IdleAnimation.Pivot.Y
+
+	return rl.NewRectangle(left+frameWidth*5/16, top+frameHeight/2, frameWidth*3/8, frameHeight/2)
+}
+
+// TakeDamage removes health from the enemy
+func (e *Enemy) TakeDamage(amount int32) {
+	e.Health -= amount
+}
+
+// IsDead reports whether the enemy has run out of health
+func (e *Enemy) IsDead() bool {
+	return e.Health <= 0
 }
 
 // Unload releases the texture resources
 func (e *Enemy) Unload() {
-	rl.UnloadTexture(e.Texture)
+	e.IdleAnimation.Unload()
+	e.WalkingAnimation.Unload()
+	e.AttackingAnimation.Unload()
 }
 
-func (e *Enemy) moveToTarget() {
+func (e *Enemy) moveToTarget(dt float32) {
 	if e.Target.Position.X < e.Position.X {
-		e.Position.X -= e.speed
+		e.Position.X -= e.speed * dt
 	} else {
-		e.Position.X += e.speed
+		e.Position.X += e.speed * dt
 	}
 }
 
-func (e *Enemy) renderTexture(texture rl.Texture2D) {
-	e.FrameWidth = float32(texture.Width) / float32(texture.Width/128) // Assuming each frame is 128x128 pixels
-	e.FrameHeight = float32(texture.Height)
-	e.FramesCount = texture.Width / 128
+// patrol walks the enemy back and forth across its patrol range, turning at
+// either end. It reports whether the enemy moved
+func (e *Enemy) patrol(dt float32) bool {
+	if e.PatrolRange <= 0 {
+		return false
+	}
+
+	if e.FacingLeft && e.Position.X <= e.home-e.PatrolRange {
+		e.FacingLeft = false
+	} else if !e.FacingLeft && e.Position.X >= e.home+e.PatrolRange {
+		e.FacingLeft = true
+	}
+
+	if e.FacingLeft {
+		e.Position.X -= e.speed * dt
+	} else {
+		e.Position.X += e.speed * dt
+	}
+	return true
 }
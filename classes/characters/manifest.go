@@ -0,0 +1,170 @@
+package characters
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/grcatterall/go-game/classes/helpers"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// charactersDir is where every character's sprite folder lives
+const charactersDir = "assets/characters"
+
+// manifestFile is the name of the sprite manifest inside a character's folder
+const manifestFile = "manifest.json"
+
+// Pivot is the point in a frame drawn at the character's position
+type Pivot struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// SpriteAnimation describes one animation sheet in a character's folder, either
+// a strip or grid of equal frames in File or a tag of an Aseprite JSON export.
+// Frame size and pivot fall back to the manifest's defaults when left out
+type SpriteAnimation struct {
+	File        string       `json:"file,omitempty"`
+	Aseprite    string       `json:"aseprite,omitempty"`
+	Tag         string       `json:"tag,omitempty"`
+	FrameWidth  int32        `json:"frameWidth,omitempty"`
+	FrameHeight int32        `json:"frameHeight,omitempty"`
+	Frames      int32        `json:"frames,omitempty"`
+	Speed       float32      `json:"speed"`
+	Durations   []float32    `json:"durations,omitempty"` // seconds per frame, overriding speed
+	Loop        string       `json:"loop"`                // loop, once, pingpong or reverse
+	Pivot       *Pivot       `json:"pivot,omitempty"`
+	Events      []FrameEvent `json:"events,omitempty"`
+}
+
+// FrameEvent names something that happens on a frame of an animation, like a gun firing
+type FrameEvent struct {
+	Frame int32  `json:"frame"`
+	Name  string `json:"name"`
+}
+
+// SpriteManifest lists every animation a character has, read from manifest.json in its folder
+type SpriteManifest struct {
+	Name        string                     `json:"name"`
+	FrameWidth  int32                      `json:"frameWidth"`
+	FrameHeight int32                      `json:"frameHeight"`
+	Pivot       Pivot                      `json:"pivot"`
+	Animations  map[string]SpriteAnimation `json:"animations"`
+	directory   string
+}
+
+// LoadManifest reads the sprite manifest for a character folder in assets/characters
+func LoadManifest(character string) (*SpriteManifest, error) {
+	directory := filepath.Join(charactersDir, character)
+
+	data, err := os.ReadFile(filepath.Join(directory, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SpriteManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s manifest: %w", character, err)
+	}
+	manifest.directory = directory
+
+	for name, animation := range manifest.Animations {
+		if _, err := helpers.ParsePlayMode(animation.Loop); err != nil {
+			return nil, fmt.Errorf("%s animation %q: %w", character, name, err)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// Characters lists every character folder in assets/characters that has a manifest
+func Characters() []string {
+	entries, err := os.ReadDir(charactersDir)
+	if err != nil {
+		return nil
+	}
+
+	var characters []string
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(charactersDir, entry.Name(), manifestFile)); entry.IsDir() && err == nil {
+			characters = append(characters, entry.Name())
+		}
+	}
+	return characters
+}
+
+// Has reports whether the character has an animation with the given name
+func (m *SpriteManifest) Has(name string) bool {
+	_, ok := m.Animations[name]
+	return ok
+}
+
+// LoadAnimation loads a named animation, an unknown name gives an empty animation that draws nothing
+func (m *SpriteManifest) LoadAnimation(name string) helpers.Animation {
+	spec, ok := m.Animations[name]
+	if !ok {
+		return helpers.Animation{}
+	}
+
+	frameWidth := spec.FrameWidth
+	if frameWidth == 0 {
+		frameWidth = m.FrameWidth
+	}
+	frameHeight := spec.FrameHeight
+	if frameHeight == 0 {
+		frameHeight = m.FrameHeight
+	}
+	pivot := m.Pivot
+	if spec.Pivot != nil {
+		pivot = *spec.Pivot
+	}
+
+	var animation helpers.Animation
+	if spec.Aseprite != "" {
+		var err error
+		animation, err = helpers.LoadAsepriteAnimation(filepath.Join(m.directory, spec.Aseprite), spec.Tag)
+		if err != nil {
+			log.Printf("sprite manifest: %s animation %q: %v", m.Name, name, err)
+			return helpers.Animation{}
+		}
+	} else {
+		animation = helpers.LoadAnimationFrames(filepath.Join(m.directory, spec.File), spec.Speed, frameWidth, frameHeight, spec.Frames)
+		animation.Durations = spec.Durations
+	}
+
+	// Aseprite tags carry their own direction unless the manifest sets one
+	if spec.Aseprite == "" || spec.Loop != "" {
+		animation.Mode, _ = helpers.ParsePlayMode(spec.Loop)
+	}
+	animation.Reset()
+	animation.Pivot = rl.Vector2{X: pivot.X, Y: pivot.Y}
+	for _, event := range spec.Events {
+		animation.AddEvent(event.Frame, event.Name)
+	}
+
+	return animation
+}
+
+// loadManifestOrEmpty loads a character's manifest, falling back to an empty one
+// so a broken manifest shows up as an invisible character rather than a crash
+func loadManifestOrEmpty(character string) *SpriteManifest {
+	manifest, err := LoadManifest(character)
+	if err != nil {
+		log.Printf("sprite manifest: %v", err)
+		return &SpriteManifest{Name: character}
+	}
+	return manifest
+}
+
+// loadAnimationOr loads a named animation, or the fallback when the character
+// doesn't have it, like traders that never walk
+func (m *SpriteManifest) loadAnimationOr(name, fallback string) helpers.Animation {
+	if !m.Has(name) {
+		return m.LoadAnimation(fallback)
+	}
+	return m.LoadAnimation(name)
+}
@@ -1,10 +1,11 @@
 package characters
 
 import (
-	"fmt"
+	"math"
 
 	"github.com/grcatterall/go-game/classes/game_manager"
 	"github.com/grcatterall/go-game/classes/helpers"
+	"github.com/grcatterall/go-game/classes/input"
 	"github.com/grcatterall/go-game/classes/objects/weapons"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -12,189 +13,310 @@ import (
 
 type Player struct {
 	Position           rl.Vector2
-	Velocity           rl.Vector2
-	Gravity            float32
-	Speed              float32
+	PreviousPosition   rl.Vector2
+	Velocity           rl.Vector2 // pixels per second
+	Gravity            float32    // pixels per second squared
+	Speed              float32    // pixels per second
+	JumpSpeed          float32    // pixels per second
 	IsMoving           bool
 	IsRunning          bool
 	IsGrounded         bool
 	IsLeft             bool
 	IsShooting         bool
 	IsAttacking        bool
+	IsHurt             bool
+	Health             int32
+	GroundFriction     float32                // grip of the ground underfoot, 1 in the air
+	Platform           *game_manager.Platform // the platform being stood on, nil off them
 	IdleAnimation      helpers.Animation
 	WalkingAnimation   helpers.Animation
 	RunningAnimation   helpers.Animation
 	ShootingAnimation  helpers.Animation
 	AttackingAnimation helpers.Animation
+	HurtAnimation      helpers.Animation
 	CurrentAnimation   *helpers.Animation
+	Animations         *helpers.AnimationStateMachine
 	Bullets            []*weapons.Bullet
+	Input              input.Controller
+	OnMeleeHit         func(area rl.Rectangle)
+	hurtTimer          float32
+	dropTimer          float32
+	onSlope            bool
 }
 
+// meleeReach is how far in front of the player's body a melee attack lands
+const meleeReach = 40
+
+// playerHealth is how much health the player starts with
+const playerHealth = 5
+
+// The player's movement, in pixels per second and pixels per second squared
+const (
+	playerSpeed     = 60
+	playerGravity   = 360
+	playerJumpSpeed = 180
+)
+
+// sprintMultiplier is how much faster the player runs while sprinting
+const sprintMultiplier = 2
+
+// hurtCooldown is how many seconds the player can't be hurt again after taking damage
+const hurtCooldown = 1.0
+
+// stepHeight is the tallest ledge a walking player steps up onto rather than bumping into
+const stepHeight = 16
+
+// slopeSnap is how far above a ramp a grounded player can be and still be kept on it
+const slopeSnap = 16
+
+// dropThroughTime is how many seconds one-way platforms stay open after the player holds down
+const dropThroughTime = 0.25
+
+// oneWayTolerance is how far into a one-way platform the player's feet can already be and still land on it
+const oneWayTolerance = 4
+
 var mainSprite = "Soldier_1"
 
-// NewPlayer creates a new player with the given sprite and position
-func NewPlayer(position rl.Vector2, frameSpeed float32) *Player {
-	return &Player{
+// NewPlayer creates a new player at the given position using the main sprite's manifest
+func NewPlayer(position rl.Vector2) *Player {
+	manifest := loadManifestOrEmpty(mainSprite)
+
+	p := &Player{
 		Position:           position,
+		PreviousPosition:   position,
 		Velocity:           rl.Vector2{X: 0, Y: 0},
-		Speed:              1.0,
-		Gravity:            0.1,
-		IdleAnimation:      helpers.LoadAnimation(fmt.Sprintf("assets/characters/%s/Idle.png", mainSprite), frameSpeed, 128),
-		WalkingAnimation:   helpers.LoadAnimation(fmt.Sprintf("assets/characters/%s/Walk.png", mainSprite), frameSpeed, 128),
-		RunningAnimation:   helpers.LoadAnimation(fmt.Sprintf("assets/characters/%s/Run.png", mainSprite), frameSpeed, 128),
-		ShootingAnimation:  helpers.LoadAnimation(fmt.Sprintf("assets/characters/%s/Shot_1.png", mainSprite), 1, 128),
-		AttackingAnimation: helpers.LoadAnimation(fmt.Sprintf("assets/characters/%s/Attack.png", mainSprite), 0.1, 128),
+		Speed:              playerSpeed,
+		Gravity:            playerGravity,
+		JumpSpeed:          playerJumpSpeed,
+		Health:             playerHealth,
+		GroundFriction:     1,
+		IdleAnimation:      manifest.LoadAnimation("idle"),
+		WalkingAnimation:   manifest.LoadAnimation("walk"),
+		RunningAnimation:   manifest.LoadAnimation("run"),
+		ShootingAnimation:  manifest.LoadAnimation("shoot"),
+		AttackingAnimation: manifest.LoadAnimation("attack"),
+		HurtAnimation:      manifest.LoadAnimation("hurt"),
 		CurrentAnimation:   nil,
 		Bullets:            []*weapons.Bullet{},
+		Input:              input.Default,
+	}
+	p.Animations = p.newAnimationStateMachine()
+	p.ShootingAnimation.On("fire", p.fire)
+	p.AttackingAnimation.On("hit", p.meleeHit)
+	p.CurrentAnimation = p.Animations.Animation()
+
+	return p
+}
+
+// locomotionFade is the cross-fade time between idle, walking and running
+const locomotionFade = 0.1
+
+// newAnimationStateMachine declares the player's animation states, getting hurt
+// interrupts shooting which interrupts melee which interrupts movement
+func (p *Player) newAnimationStateMachine() *helpers.AnimationStateMachine {
+	sm := helpers.NewAnimationStateMachine()
+
+	sm.AddState("idle", &p.IdleAnimation, false, 0)
+	sm.AddState("walk", &p.WalkingAnimation, false, 0)
+	sm.AddState("run", &p.RunningAnimation, false, 0)
+	sm.AddState("attack", &p.AttackingAnimation, true, 1)
+	sm.AddState("shoot", &p.ShootingAnimation, true, 2)
+	sm.AddState("hurt", &p.HurtAnimation, true, 3)
+
+	sm.AddTransition(helpers.AnyState, "hurt", 0, func() bool { return p.IsHurt })
+	sm.AddTransition(helpers.AnyState, "shoot", 0, func() bool { return p.IsShooting })
+	sm.AddTransition(helpers.AnyState, "attack", 0, func() bool { return p.IsAttacking })
+	sm.AddTransition(helpers.AnyState, "run", locomotionFade, func() bool { return p.IsMoving && p.IsRunning })
+	sm.AddTransition(helpers.AnyState, "walk", locomotionFade, func() bool { return p.IsMoving && !p.IsRunning })
+	sm.AddTransition(helpers.AnyState, "idle", locomotionFade, func() bool { return !p.IsMoving })
+
+	sm.OnFinished = func(state string) {
+		switch state {
+		case "shoot":
+			p.IsShooting = false
+		case "attack":
+			p.IsAttacking = false
+		case "hurt":
+			p.IsHurt = false
+		}
 	}
+
+	return sm
 }
 
-// Update updates the player animation and movement
-func (p *Player) Update(tileMap *game_manager.TileMap) {
-	p.Velocity.Y += p.Gravity
+// Update advances the player animation and movement by dt seconds
+func (p *Player) Update(tileMap *game_manager.TileMap, dt float32) {
+	p.PreviousPosition = p.Position
+	p.Velocity.Y += p.Gravity * dt
 
-	p.updateAnimation()
-	p.updateMovement()
+	p.updateMovement(dt)
 	p.updateActions()
-	p.selectCurrentAnimation()
-	p.updateFrameRec()
+	p.updateAnimation(dt)
+
+	if p.hurtTimer > 0 {
+		p.hurtTimer -= dt
+	}
+	if p.dropTimer > 0 {
+		p.dropTimer -= dt
+	}
 
 	// Update bullets
 	for _, bullet := range p.Bullets {
-		bullet.Update()
+		bullet.Update(dt)
 	}
 
 	// Remove inactive bullets
 	p.Bullets = filterActiveBullets(p.Bullets)
 
 	// Apply velocity to position
-	p.Position.X += p.Velocity.X
-	p.Position.Y += p.Velocity.Y
+	p.Position.X += p.Velocity.X * dt
+	p.Position.Y += p.Velocity.Y * dt
 }
 
-// updateAnimation updates the animation frames
-func (p *Player) updateAnimation() {
-	if p.CurrentAnimation == nil {
-		return
-	}
+// Jump returns how far the player can jump, moving the way updateMovement does
+func (p *Player) Jump() game_manager.Jump {
+	return game_manager.Jump{Speed: p.Speed * sprintMultiplier, Gravity: p.Gravity, JumpSpeed: p.JumpSpeed}
+}
 
-	p.CurrentAnimation.FrameCounter += p.CurrentAnimation.FrameSpeed
-	if p.CurrentAnimation.FrameCounter >= 1 {
-		p.CurrentAnimation.FrameCounter = 0
-		p.CurrentAnimation.CurrentFrame++
-		if p.CurrentAnimation.CurrentFrame >= p.CurrentAnimation.Frames {
-			p.CurrentAnimation.CurrentFrame = 0
-			if p.IsShooting || p.IsAttacking {
-				p.IsShooting = false
-				p.IsAttacking = false
-			}
-		}
-		p.CurrentAnimation.FrameRec.X = float32(p.CurrentAnimation.CurrentFrame) * p.CurrentAnimation.FrameRec.Width
-	}
+// PlayerJump returns how far a new player can jump
+func PlayerJump() game_manager.Jump {
+	player := Player{Speed: playerSpeed, Gravity: playerGravity, JumpSpeed: playerJumpSpeed}
+	return player.Jump()
+}
+
+// updateAnimation lets the state machine pick the animation for the player's state and advances it by dt seconds
+func (p *Player) updateAnimation(dt float32) {
+	p.Animations.Update(dt)
+	p.CurrentAnimation = p.Animations.Animation()
 }
 
-// updateMovement updates the player's position based on input
-func (p *Player) updateMovement() {
-	if rl.IsKeyDown(rl.KeySpace) && p.IsGrounded {
-		p.Velocity.Y = -3
+// updateMovement updates the player's velocity based on input, easing towards
+// the wanted speed on surfaces with less than full grip
+func (p *Player) updateMovement(dt float32) {
+	if p.Input.Held(input.Jump) && p.IsGrounded {
+		p.Velocity.Y = -p.JumpSpeed
 		p.IsGrounded = false
 	}
 
-	if (rl.IsKeyDown(rl.KeyD) || rl.IsKeyDown(rl.KeyA)) && !p.IsShooting {
+	var targetVelocity float32
+
+	if (p.Input.Held(input.MoveRight) || p.Input.Held(input.MoveLeft)) && !p.IsShooting {
 		p.IsMoving = true
 		p.IsRunning = false
 
 		var speed = p.Speed
 
-		if rl.IsKeyDown(rl.KeyLeftShift) {
+		if p.Input.Held(input.Sprint) {
 			p.IsRunning = true
-			speed *= 2
+			speed *= sprintMultiplier
 		}
 
-		if rl.IsKeyDown(rl.KeyD) {
+		if p.Input.Held(input.MoveRight) {
 			p.IsLeft = false
-			p.Velocity.X = speed
+			targetVelocity = speed
 		}
-		if rl.IsKeyDown(rl.KeyA) {
+		if p.Input.Held(input.MoveLeft) {
 			p.IsLeft = true
-			p.Velocity.X = -speed
+			targetVelocity = -speed
 		}
 	} else {
 		p.IsMoving = false
-		p.Velocity.X = 0
 	}
+
+	grip := float32(1)
+	if p.IsGrounded {
+		grip = min(max(p.GroundFriction, 0), 1)
+	}
+
+	// Full grip reaches the wanted speed straight away, less grip closes the same
+	// share of the gap every 60th of a second whatever the tick rate
+	blend := 1 - float32(math.Pow(float64(1-grip), float64(dt*60)))
+	p.Velocity.X += (targetVelocity - p.Velocity.X) * blend
 }
 
 // updateActions handles shooting and attacking actions
 func (p *Player) updateActions() {
-	if rl.IsMouseButtonDown(0) && !p.IsShooting {
+	// The bullet and the melee hit come later, from the animations' "fire" and "hit" frames
+	if p.Input.Held(input.Shoot) && !p.IsShooting {
 		p.IsShooting = true
-		p.ShootingAnimation.CurrentFrame = 0
-		p.ShootingAnimation.FrameCounter = 0
-		p.Bullets = append(p.Bullets, weapons.SpawnBullet(rl.Vector2{X: p.Position.X + 16, Y: p.Position.Y + 88}, p.IsLeft))
-
 	}
 
-	if rl.IsMouseButtonDown(1) && !p.IsAttacking {
+	if p.Input.Held(input.Melee) && !p.IsAttacking {
 		p.IsAttacking = true
-		p.AttackingAnimation.CurrentFrame = 0
-		p.AttackingAnimation.FrameCounter = 0
 	}
 }
 
-// selectCurrentAnimation selects the appropriate animation based on player state
-func (p *Player) selectCurrentAnimation() {
-	switch {
-	case p.IsShooting:
-		p.CurrentAnimation = &p.ShootingAnimation
-	case p.IsAttacking:
-		p.CurrentAnimation = &p.AttackingAnimation
-	case p.IsMoving:
-		if p.IsRunning {
-			p.CurrentAnimation = &p.RunningAnimation
-		} else {
-			p.CurrentAnimation = &p.WalkingAnimation
-		}
-	default:
-		p.CurrentAnimation = &p.IdleAnimation
+// fire spawns a bullet from the gun's muzzle
+func (p *Player) fire() {
+	p.Bullets = append(p.Bullets, weapons.SpawnBullet(rl.Vector2{X: p.Position.X + 16, Y: p.Position.Y + 88}, p.IsLeft))
+}
+
+// meleeHit reports the area in front of the player that the melee attack struck
+func (p *Player) meleeHit() {
+	if p.OnMeleeHit == nil {
+		return
 	}
+
+	bodyRect, _ := p.collisionRects()
+	area := rl.NewRectangle(bodyRect.X+bodyRect.Width, bodyRect.Y, meleeReach, bodyRect.Height)
+	if p.IsLeft {
+		area.X = bodyRect.X - meleeReach
+	}
+	p.OnMeleeHit(area)
 }
 
-// updateFrameRec updates the frame rectangle for the current animation
-func (p *Player) updateFrameRec() {
-	if p.CurrentAnimation != nil {
-		p.CurrentAnimation.FrameRec.Width = float32(p.CurrentAnimation.Texture.Width) / float32(p.CurrentAnimation.Frames)
-		p.CurrentAnimation.FrameRec.Height = float32(p.CurrentAnimation.Texture.Height)
+// RenderPosition returns the player's position interpolated alpha of the way between the last two ticks
+func (p *Player) RenderPosition(alpha float32) rl.Vector2 {
+	return rl.Vector2{
+		X: helpers.Lerp(p.PreviousPosition.X, p.Position.X, alpha),
+		Y: helpers.Lerp(p.PreviousPosition.Y, p.Position.Y, alpha),
 	}
 }
 
-// Draw renders the player sprite on the screen
-func (p *Player) Draw() {
+// Draw renders the player sprite and bullets on the screen
+func (p *Player) Draw(alpha float32) {
+	for _, bullet := range p.Bullets {
+		bullet.Draw(alpha)
+	}
+
 	if p.CurrentAnimation == nil {
 		return
 	}
 
+	renderPosition := p.RenderPosition(alpha)
+
+	// Fade the animation being switched away from out underneath the new one
+	if previous, progress := p.Animations.CrossFade(); previous != nil {
+		p.drawFrame(previous, renderPosition, rl.Fade(rl.White, 1-progress))
+	}
+
+	p.drawFrame(p.CurrentAnimation, renderPosition, rl.White)
+}
+
+// drawFrame draws an animation's current frame at the player's position, facing the way the player faces
+func (p *Player) drawFrame(animation *helpers.Animation, position rl.Vector2, tint rl.Color) {
 	flipX := true
 	if p.IsLeft {
 		flipX = false
 	}
 
-	drawPosition := rl.Vector2{X: p.Position.X, Y: p.Position.Y}
+	drawPosition := rl.Vector2Subtract(position, animation.Origin())
 
 	if flipX {
-		rl.DrawTextureRec(p.CurrentAnimation.Texture, p.CurrentAnimation.FrameRec, drawPosition, rl.White)
+		rl.DrawTextureRec(animation.Texture, animation.FrameRec, drawPosition, tint)
 	} else {
-		rl.DrawTextureRec(p.CurrentAnimation.Texture, rl.Rectangle{X: p.CurrentAnimation.FrameRec.X + p.CurrentAnimation.FrameRec.Width, Y: p.CurrentAnimation.FrameRec.Y, Width: -p.CurrentAnimation.FrameRec.Width, Height: p.CurrentAnimation.FrameRec.Height}, drawPosition, rl.White)
+		rl.DrawTextureRec(animation.Texture, rl.Rectangle{X: animation.FrameRec.X + animation.FrameRec.Width, Y: animation.FrameRec.Y, Width: -animation.FrameRec.Width, Height: animation.FrameRec.Height}, drawPosition, tint)
 	}
 }
 
 // Unload releases the texture resources
 func (p *Player) Unload() {
-	rl.UnloadTexture(p.IdleAnimation.Texture)
-	rl.UnloadTexture(p.WalkingAnimation.Texture)
-	rl.UnloadTexture(p.RunningAnimation.Texture)
-	rl.UnloadTexture(p.ShootingAnimation.Texture)
-	rl.UnloadTexture(p.AttackingAnimation.Texture)
+	p.IdleAnimation.Unload()
+	p.WalkingAnimation.Unload()
+	p.RunningAnimation.Unload()
+	p.ShootingAnimation.Unload()
+	p.AttackingAnimation.Unload()
+	p.HurtAnimation.Unload()
 }
 
 // filterActiveBullets removes inactive bullets from the list
@@ -208,94 +330,270 @@ func filterActiveBullets(bullets []*weapons.Bullet) []*weapons.Bullet {
 	return activeBullets
 }
 
-func (p *Player) CheckCollisions(tileMap *game_manager.TileMap, camera rl.Camera2D) {
-	// Adjust the player position by the camera offset
-	adjustedPosition := rl.Vector2{
-		X: p.Position.X - camera.Target.X + camera.Offset.X,
-		Y: p.Position.Y - camera.Target.Y + camera.Offset.Y,
+// CheckCollisions resolves the player against the tiles around it, in world
+// coordinates, following each tile's shape, and lands them on platforms. It
+// also picks up the grip of the ground underfoot and the damage of any hazard
+// being touched
+func (p *Player) CheckCollisions(tileMap *game_manager.TileMap, platforms []*game_manager.Platform) {
+	if p.CurrentAnimation == nil {
+		return
+	}
+
+	// Calculate the body and feet rectangles relative to the player position
+	bodyRect, feetRect := p.collisionRects()
+
+	frameHeight := p.CurrentAnimation.FrameRec.Height
+	bottom := p.Position.Y + frameHeight
+	wasGrounded := p.IsGrounded
+	var damage int32
+
+	p.forEachNearbyTile(tileMap, func(tile *game_manager.Tile, tileRect rl.Rectangle) {
+		touchingBody := rl.CheckCollisionRecs(bodyRect, tileRect)
+		if (touchingBody || rl.CheckCollisionRecs(feetRect, tileRect)) && tile.Definition.Damage > damage {
+			damage = tile.Definition.Damage
+		}
+
+		// Only solid blocks stop the player sideways, and low ledges are stepped up
+		// onto instead. On a ramp the body reaches the block at the top before the
+		// feet do, so anything up to a tile high is stepped onto
+		step := float32(stepHeight)
+		if p.onSlope {
+			step = tileRect.Height
+		}
+		if tile.Definition.Shape != game_manager.ShapeSolid || (wasGrounded && tileRect.Y >= bottom-step) {
+			return
+		}
+
+		// Check horizontal collisions with the body rectangle
+		if touchingBody {
+			// Handle horizontal collisions
+			if p.Velocity.X > 0 { // Moving right
+				p.Position.X = tileRect.X - p.CurrentAnimation.FrameRec.Width + 35
+				p.Velocity.X = 0
+			} else if p.Velocity.X < 0 { // Moving left
+				p.Position.X = tileRect.X + tileRect.Width - 41
+				p.Velocity.X = 0
+			}
+		}
+	})
+
+	// Sideways collisions may have moved the player, so measure the feet again
+	_, feetRect = p.collisionRects()
+	footX := feetRect.X + feetRect.Width/2
+	previousBottom := p.PreviousPosition.Y + frameHeight
+
+	// Holding down drops through one-way platforms, for long enough to fall clear of them
+	if p.Input.Held(input.MoveDown) {
+		p.dropTimer = dropThroughTime
 	}
 
-	// Calculate the body and feet rectangles relative to the adjusted player position
-	feetRect := rl.NewRectangle(adjustedPosition.X+47, adjustedPosition.Y+p.CurrentAnimation.FrameRec.Height*0.9, p.CurrentAnimation.FrameRec.Width-90, p.CurrentAnimation.FrameRec.Height*0.2)
-	bodyRect := rl.NewRectangle(adjustedPosition.X+40, adjustedPosition.Y+64, p.CurrentAnimation.FrameRec.Width-75, p.CurrentAnimation.FrameRec.Height*0.9-64)
+	var ground, slope *game_manager.Tile
+	var groundY, slopeY float32
+	var groundUnderFoot bool
+
+	p.forEachNearbyTile(tileMap, func(tile *game_manager.Tile, tileRect rl.Rectangle) {
+		// Nothing catches the player on the way up
+		if p.Velocity.Y < 0 {
+			return
+		}
+
+		definition := tile.Definition
+		underFoot := footX >= tileRect.X && footX < tileRect.X+tileRect.Width
+
+		switch {
+		case definition.Shape == game_manager.ShapeSolid:
+			if !rl.CheckCollisionRecs(feetRect, tileRect) {
+				return
+			}
+		case definition.Shape == game_manager.ShapeOneWay:
+			// Only land from above
+			if !rl.CheckCollisionRecs(feetRect, tileRect) || p.dropTimer > 0 || previousBottom > tileRect.Y+oneWayTolerance {
+				return
+			}
+		case definition.Shape.IsSlope():
+			if !underFoot {
+				return
+			}
+			surface := definition.SurfaceY(tileRect.X, tileRect.Y, tileRect.Width, footX)
+
+			// Catch feet that reach the ramp, and keep a grounded player on it walking downhill
+			snap := float32(0)
+			if wasGrounded {
+				snap = slopeSnap
+			}
+			if bottom < surface-snap || bottom > tileRect.Y+tileRect.Height+oneWayTolerance {
+				return
+			}
+			if slope == nil || surface < slopeY {
+				slope = tile
+				slopeY = surface
+			}
+			return
+		default:
+			return
+		}
+
+		// On a ramp, flat tiles the edge of the feet brush against don't count
+		if slope == nil || underFoot {
+			if ground == nil || tileRect.Y < groundY {
+				ground = tile
+				groundY = tileRect.Y
+				groundUnderFoot = underFoot
+			}
+		}
+	})
+
+	// A ramp under the player's feet beats flat tiles beside it, unless one is higher under the same foot
+	if slope != nil && (ground == nil || !groundUnderFoot || slopeY < groundY) {
+		ground = slope
+		groundY = slopeY
+	}
+
+	// Platforms catch the player like one-way tiles, allowing for how far they
+	// moved this tick, and keep hold of a player already riding them
+	var platform *game_manager.Platform
+	for _, candidate := range platforms {
+		area := candidate.Area
+		if p.Velocity.Y < 0 || p.dropTimer > 0 || !candidate.Solid() || !rl.CheckCollisionRecs(feetRect, area) {
+			continue
+		}
+		if candidate != p.Platform && previousBottom > area.Y-candidate.Delta.Y+oneWayTolerance {
+			continue
+		}
+		if (ground == nil || area.Y < groundY) && (platform == nil || area.Y < platform.Area.Y) {
+			platform = candidate
+		}
+	}
+
+	p.IsGrounded = false
+	p.GroundFriction = 1
+	p.onSlope = false
+	p.Platform = platform
+
+	switch {
+	case platform != nil:
+		p.Position.Y = platform.Area.Y - frameHeight
+		p.Velocity.Y = 0
+		p.IsGrounded = true
+		p.GroundFriction = platform.Friction
+	case ground != nil:
+		p.Position.Y = groundY - frameHeight
+		p.Velocity.Y = 0
+		p.IsGrounded = true
+		p.GroundFriction = ground.Definition.Friction
+		p.onSlope = ground.Definition.Shape.IsSlope()
+	}
+
+	if damage > 0 {
+		p.TakeDamage(damage)
+	}
+}
+
+// TakeDamage removes health from the player, unless they were hurt moments ago
+func (p *Player) TakeDamage(amount int32) {
+	if p.hurtTimer > 0 || p.IsDead() {
+		return
+	}
+
+	p.Health -= amount
+	p.IsHurt = true
+	p.hurtTimer = hurtCooldown
+}
+
+// Heal gives the player back health, up to what they started with
+func (p *Player) Heal(amount int32) {
+	p.Health = min(p.Health+amount, playerHealth)
+}
+
+// Respawn puts the player back on their feet at a position with some health,
+// standing still and unhurt, not shooting or attacking, with their bullets gone
+// and the idle animation playing
+func (p *Player) Respawn(position rl.Vector2, health int32) {
+	p.Position, p.PreviousPosition = position, position
+	p.Velocity = rl.Vector2{}
+	p.Health = health
+	p.IsMoving, p.IsRunning, p.IsGrounded = false, false, false
+	p.IsShooting, p.IsAttacking, p.IsHurt = false, false, false
+	p.Platform = nil
+	p.onSlope = false
+	p.hurtTimer, p.dropTimer = 0, 0
+	p.Bullets = []*weapons.Bullet{}
+	p.Animations.Play("idle")
+	p.CurrentAnimation = p.Animations.Animation()
+}
+
+// IsDead reports whether the player has run out of health
+func (p *Player) IsDead() bool {
+	return p.Health <= 0
+}
+
+// DrawDebug outlines the player's collision rectangles and the tiles checked against them,
+// call it inside BeginMode2D
+func (p *Player) DrawDebug(tileMap *game_manager.TileMap) {
+	if p.CurrentAnimation == nil {
+		return
+	}
+
+	bodyRect, feetRect := p.collisionRects()
 
 	// Draw the rectangles for debugging
 	rl.DrawRectangleLines(int32(bodyRect.X), int32(bodyRect.Y), int32(bodyRect.Width), int32(bodyRect.Height), rl.Green)
 	rl.DrawRectangleLines(int32(feetRect.X), int32(feetRect.Y), int32(feetRect.Width), int32(feetRect.Height), rl.Green)
 
+	p.forEachNearbyTile(tileMap, func(tile *game_manager.Tile, tileRect rl.Rectangle) {
+		highlightColor := rl.Green // Default color for checked tiles
+		if rl.CheckCollisionRecs(bodyRect, tileRect) || rl.CheckCollisionRecs(feetRect, tileRect) {
+			highlightColor = rl.Red // Color for tiles with collision
+		}
+
+		// Draw a rectangle around the tile being checked
+		rl.DrawRectangleLines(int32(tileRect.X), int32(tileRect.Y), int32(tileRect.Width), int32(tileRect.Height), highlightColor)
+	})
+}
+
+// Bounds returns the player's body in world coordinates
+func (p *Player) Bounds() rl.Rectangle {
+	if p.CurrentAnimation == nil {
+		return rl.NewRectangle(p.Position.X, p.Position.Y, 0, 0)
+	}
+	bodyRect, _ := p.collisionRects()
+	return bodyRect
+}
+
+// Touches reports whether the player's body or feet overlap an area
+func (p *Player) Touches(area rl.Rectangle) bool {
+	if p.CurrentAnimation == nil {
+		return false
+	}
+	bodyRect, feetRect := p.collisionRects()
+	return rl.CheckCollisionRecs(bodyRect, area) || rl.CheckCollisionRecs(feetRect, area)
+}
+
+// collisionRects returns the player's body and feet rectangles in world coordinates
+func (p *Player) collisionRects() (rl.Rectangle, rl.Rectangle) {
+	frameRec := p.CurrentAnimation.FrameRec
+
+	bodyRect := rl.NewRectangle(p.Position.X+40, p.Position.Y+64, frameRec.Width-75, frameRec.Height*0.9-64)
+	feetRect := rl.NewRectangle(p.Position.X+47, p.Position.Y+frameRec.Height*0.9, frameRec.Width-90, frameRec.Height*0.2)
+
+	return bodyRect, feetRect
+}
+
+// forEachNearbyTile calls fn for every solid tile overlapping the player's sprite
+func (p *Player) forEachNearbyTile(tileMap *game_manager.TileMap, fn func(tile *game_manager.Tile, tileRect rl.Rectangle)) {
 	// Calculate the range of tiles to check
-	tileWidth := 32  // Assuming each tile is 32 pixels wide
-	tileHeight := 32 // Assuming each tile is 32 pixels high
+	tileWidth := int(tileMap.TileWidth)
+	tileHeight := int(tileMap.TileHeight)
 
 	startX := int(p.Position.X) / tileWidth
 	endX := (int(p.Position.X) + int(p.CurrentAnimation.FrameRec.Width)) / tileWidth
 	startY := int(p.Position.Y) / tileHeight
 	endY := (int(p.Position.Y) + int(p.CurrentAnimation.FrameRec.Height)) / tileHeight
 
-	p.IsGrounded = false
-
-	for y := startY; y <= endY; y++ {
-		for x := startX; x <= endX; x++ {
-			if y >= 0 && y < len(tileMap.Tiles) && x >= 0 && x < len(tileMap.Tiles[y]) {
-				tile := tileMap.Tiles[y][x]
-				if tile != nil {
-					// Adjust the tile position by the camera offset
-					adjustedTilePosition := rl.Vector2{
-						X: tile.Position.X - camera.Target.X + camera.Offset.X,
-						Y: tile.Position.Y - camera.Target.Y + camera.Offset.Y,
-					}
-					tileRect := rl.NewRectangle(adjustedTilePosition.X, adjustedTilePosition.Y, float32(tileWidth), float32(tileHeight))
-					highlightColor := rl.Green // Default color for checked tiles
-
-					// Check horizontal collisions with the body rectangle
-					if rl.CheckCollisionRecs(bodyRect, tileRect) {
-						highlightColor = rl.Red // Color for tiles with collision
-
-						// Handle horizontal collisions
-						if p.Velocity.X > 0 { // Moving right
-							p.Position.X = tile.Position.X - p.CurrentAnimation.FrameRec.Width + 35
-							p.Velocity.X = 0
-						} else if p.Velocity.X < 0 { // Moving left
-							p.Position.X = tile.Position.X + tileRect.Width - 41
-							p.Velocity.X = 0
-						}
-					}
-
-					// Draw a rectangle around the tile being checked
-					rl.DrawRectangleLines(int32(tileRect.X), int32(tileRect.Y), int32(tileRect.Width), int32(tileRect.Height), highlightColor)
-				}
-			}
-		}
-	}
-
 	for y := startY; y <= endY; y++ {
 		for x := startX; x <= endX; x++ {
-			if y >= 0 && y < len(tileMap.Tiles) && x >= 0 && x < len(tileMap.Tiles[y]) {
-				tile := tileMap.Tiles[y][x]
-				if tile != nil {
-					// Adjust the tile position by the camera offset
-					adjustedTilePosition := rl.Vector2{
-						X: tile.Position.X - camera.Target.X + camera.Offset.X,
-						Y: tile.Position.Y - camera.Target.Y + camera.Offset.Y,
-					}
-					tileRect := rl.NewRectangle(adjustedTilePosition.X, adjustedTilePosition.Y, float32(tileWidth), float32(tileHeight))
-					highlightColor := rl.Green // Default color for checked tiles
-
-					// Check vertical collisions with the feet rectangle
-					if rl.CheckCollisionRecs(feetRect, tileRect) {
-						highlightColor = rl.Red // Color for tiles with collision
-
-						// Handle vertical collisions
-						if p.Velocity.Y > 0 { // Falling down
-							p.Position.Y = tile.Position.Y - p.CurrentAnimation.FrameRec.Height
-							p.Velocity.Y = 0
-							p.IsGrounded = true
-						}
-					}
-
-					// Draw a rectangle around the tile being checked
-					rl.DrawRectangleLines(int32(tileRect.X), int32(tileRect.Y), int32(tileRect.Width), int32(tileRect.Height), highlightColor)
-				}
+			if tile := tileMap.Tiles.At(x, y); tile != nil {
+				fn(tile, tileMap.Tiles.CellRect(x, y))
 			}
 		}
 	}
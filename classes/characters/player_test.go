@@ -0,0 +1,181 @@
+package characters
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Sprite manifests and textures are found from the root of the repository
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+const tick = 1.0 / 60
+
+// groundTop is the top of the floor in flatGround
+const groundTop = 10 * game_manager.TileSize
+
+// flatGround is a map 40 tiles wide with a solid floor at row 10
+func flatGround() *game_manager.TileMap {
+	level := make([][]int, 12)
+	for y := range level {
+		level[y] = make([]int, 40)
+	}
+	for x := range level[10] {
+		level[10][x] = 1
+	}
+	return game_manager.LoadGoLevel(level, nil)
+}
+
+// step advances the player a tick holding actions, the way the world does
+func step(p *Player, state *input.State, tileMap *game_manager.TileMap, actions ...input.Action) {
+	var current uint32
+	for _, action := range actions {
+		current |= 1 << uint(action)
+	}
+	state.Advance(current)
+	p.Update(tileMap, tick)
+	p.CheckCollisions(tileMap, nil)
+}
+
+// landedPlayer returns a player driven by a State, standing on flatGround
+func landedPlayer(t *testing.T, tileMap *game_manager.TileMap) (*Player, *input.State) {
+	t.Helper()
+	state := &input.State{}
+	p := NewPlayer(rl.Vector2{X: 100, Y: groundTop - 200})
+	p.Input = state
+	t.Cleanup(p.Unload)
+
+	for i := 0; i < 120 && !p.IsGrounded; i++ {
+		step(p, state, tileMap)
+	}
+	if !p.IsGrounded {
+		t.Fatalf("player never landed, at %v", p.Position)
+	}
+	return p, state
+}
+
+func TestPlayerLands(t *testing.T) {
+	tileMap := flatGround()
+	p, _ := landedPlayer(t, tileMap)
+
+	if bottom := p.Position.Y + p.CurrentAnimation.FrameRec.Height; bottom != groundTop {
+		t.Errorf("player stands with their feet at %v, want %v", bottom, float32(groundTop))
+	}
+	if p.Velocity.Y != 0 {
+		t.Errorf("landed player is falling at %v", p.Velocity.Y)
+	}
+}
+
+func TestPlayerRunsRight(t *testing.T) {
+	tileMap := flatGround()
+	p, state := landedPlayer(t, tileMap)
+	start := p.Position
+
+	const ticks = 30
+	for i := 0; i < ticks; i++ {
+		step(p, state, tileMap, input.MoveRight)
+	}
+
+	if p.Velocity.X != playerSpeed {
+		t.Errorf("velocity %v, want %v", p.Velocity.X, float32(playerSpeed))
+	}
+	if moved, want := p.Position.X-start.X, float32(playerSpeed*ticks*tick); !near(moved, want) {
+		t.Errorf("moved %v, want %v", moved, want)
+	}
+	if p.Position.Y != start.Y || !p.IsGrounded || p.IsLeft {
+		t.Errorf("player left the ground or turned round: %v grounded %v left %v", p.Position, p.IsGrounded, p.IsLeft)
+	}
+
+	for i := 0; i < ticks; i++ {
+		step(p, state, tileMap, input.MoveRight, input.Sprint)
+	}
+	if p.Velocity.X != playerSpeed*sprintMultiplier {
+		t.Errorf("sprinting velocity %v, want %v", p.Velocity.X, float32(playerSpeed*sprintMultiplier))
+	}
+}
+
+func TestPlayerJumps(t *testing.T) {
+	tileMap := flatGround()
+	p, state := landedPlayer(t, tileMap)
+	start := p.Position
+
+	step(p, state, tileMap, input.MoveRight, input.Jump)
+	if p.IsGrounded || p.Velocity.Y >= 0 {
+		t.Fatalf("player didn't take off: grounded %v velocity %v", p.IsGrounded, p.Velocity)
+	}
+
+	// Holding the jump, the player rises JumpSpeed²/2g then comes back down
+	highest := p.Position.Y
+	ticks := 1
+	for ; ticks < 180 && !p.IsGrounded; ticks++ {
+		step(p, state, tileMap, input.MoveRight, input.Jump)
+		highest = min(highest, p.Position.Y)
+		if p.IsGrounded {
+			break
+		}
+	}
+
+	if rise, want := start.Y-highest, float32(playerJumpSpeed*playerJumpSpeed/(2*playerGravity)); rise < want-4 || rise > want+4 {
+		t.Errorf("jump rose %v, want about %v", rise, want)
+	}
+	if !p.IsGrounded || p.Position.Y != start.Y {
+		t.Errorf("player didn't land back on the ground: %v grounded %v", p.Position, p.IsGrounded)
+	}
+	if airtime, want := float32(ticks)*tick, float32(2*playerJumpSpeed/playerGravity); airtime < want-0.1 || airtime > want+0.1 {
+		t.Errorf("jump lasted %vs, want about %vs", airtime, want)
+	}
+	if p.Position.X <= start.X {
+		t.Errorf("player didn't move right while jumping: %v", p.Position.X)
+	}
+}
+
+// near reports whether two distances are within a pixel
+func near(a, b float32) bool {
+	return a > b-1 && a < b+1
+}
+
+func TestPlayerRespawnsAtRest(t *testing.T) {
+	tileMap := flatGround()
+	p, state := landedPlayer(t, tileMap)
+
+	// Die mid-jump, shooting and attacking, just after getting hurt
+	for i := 0; i < 10; i++ {
+		step(p, state, tileMap, input.MoveRight, input.Jump, input.Shoot, input.Melee)
+	}
+	p.TakeDamage(p.Health)
+	step(p, state, tileMap, input.MoveRight, input.Shoot, input.Melee)
+	if !p.IsHurt || !p.IsShooting || !p.IsAttacking || p.Animations.Current() != "hurt" {
+		t.Fatalf("player isn't mid-action: hurt %v shooting %v attacking %v playing %q", p.IsHurt, p.IsShooting, p.IsAttacking, p.Animations.Current())
+	}
+
+	spawn := rl.Vector2{X: 300, Y: groundTop - 200}
+	p.Respawn(spawn, 3)
+
+	if p.Position != spawn || p.Velocity != (rl.Vector2{}) || p.Health != 3 {
+		t.Errorf("respawned at %v moving %v with %d health", p.Position, p.Velocity, p.Health)
+	}
+	if p.IsHurt || p.IsShooting || p.IsAttacking || p.IsMoving || len(p.Bullets) != 0 {
+		t.Errorf("player respawned mid-action: hurt %v shooting %v attacking %v moving %v bullets %d", p.IsHurt, p.IsShooting, p.IsAttacking, p.IsMoving, len(p.Bullets))
+	}
+	if p.Animations.Current() != "idle" || p.CurrentAnimation != &p.IdleAnimation {
+		t.Errorf("respawned playing %q", p.Animations.Current())
+	}
+
+	// Standing still, the player lands and stays idle
+	for i := 0; i < 120 && !p.IsGrounded; i++ {
+		step(p, state, tileMap)
+	}
+	step(p, state, tileMap)
+	if !p.IsGrounded || p.Animations.Current() != "idle" {
+		t.Errorf("after respawning the player is grounded %v playing %q", p.IsGrounded, p.Animations.Current())
+	}
+}
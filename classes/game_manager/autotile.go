@@ -0,0 +1,275 @@
+package game_manager
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Neighbours says which of a cell's eight neighbours are the same terrain, in
+// the order Tiled lists a Wang tile's sides: top, top right, right, bottom
+// right, bottom, bottom left, left and top left
+type Neighbours [8]bool
+
+// neighbourOffsets are the grid offsets of each entry of Neighbours
+var neighbourOffsets = [8][2]int{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+// NeighboursOf looks around a cell, same reports whether a cell is the same terrain
+func NeighboursOf(x, y int, same func(x, y int) bool) Neighbours {
+	var neighbours Neighbours
+	for i, offset := range neighbourOffsets {
+		neighbours[i] = same(x+offset[0], y+offset[1])
+	}
+	return neighbours
+}
+
+// Terrain is a kind of ground painted as one thing, its edge, corner and fill
+// tiles are chosen from which neighbours are the same terrain. Terrains are the
+// colours of the Wang sets in a Tiled tileset
+type Terrain struct {
+	Name  string
+	tiles []terrainTile
+	used  [8]bool // the sides and corners any of its tiles care about
+}
+
+// terrainTile is a tile of a terrain and the sides it joins up with the terrain on
+type terrainTile struct {
+	id    uint32
+	sides [8]bool
+}
+
+// Has reports whether a tile of the tileset belongs to the terrain
+func (t *Terrain) Has(id uint32) bool {
+	for _, tile := range t.tiles {
+		if tile.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Choose returns the tile of the terrain that fits its neighbours best, the
+// first one listed when several fit as well
+func (t *Terrain) Choose(neighbours Neighbours) uint32 {
+	want := wanted(neighbours)
+	best, bestScore := uint32(0), -1
+	for _, tile := range t.tiles {
+		if score := t.score(tile, want); score > bestScore {
+			best, bestScore = tile.id, score
+		}
+	}
+	return best
+}
+
+// Fits reports whether a tile of the terrain fits its neighbours as well as
+// any other, so hand picked variants of a tile can be kept
+func (t *Terrain) Fits(id uint32, neighbours Neighbours) bool {
+	want := wanted(neighbours)
+	score, best := -1, -1
+	for _, tile := range t.tiles {
+		if tile.id == id {
+			score = t.score(tile, want)
+		}
+		best = max(best, t.score(tile, want))
+	}
+	return score >= 0 && score == best
+}
+
+// wanted returns the sides a tile should join up with the terrain on. A corner
+// only counts when both sides next to it are the same terrain too, which is how
+// edge and fill tiles are drawn
+func wanted(neighbours Neighbours) [8]bool {
+	var want [8]bool
+	for i := range want {
+		want[i] = neighbours[i]
+		if i%2 == 1 {
+			want[i] = want[i] && neighbours[i-1] && neighbours[(i+1)%8]
+		}
+	}
+	return want
+}
+
+// score counts the sides a tile gets right
+func (t *Terrain) score(tile terrainTile, want [8]bool) int {
+	score := 0
+	for i := range want {
+		if t.used[i] && tile.sides[i] == want[i] {
+			score++
+		}
+	}
+	return score
+}
+
+// tiledWangSet is a Wang set of a tileset, what Tiled's terrain brush paints with
+type tiledWangSet struct {
+	Name      string           `json:"name"`
+	Type      string           `json:"type"`
+	Tile      int              `json:"tile"`
+	Colors    []tiledWangColor `json:"colors"`
+	WangTiles []tiledWangTile  `json:"wangtiles"`
+}
+
+type tiledWangColor struct {
+	Name        string  `json:"name"`
+	Color       string  `json:"color"`
+	Tile        int     `json:"tile"`
+	Probability float64 `json:"probability"`
+}
+
+// tiledWangTile gives the colour of each side and corner of a tile, 0 for none
+type tiledWangTile struct {
+	TileID uint32   `json:"tileid"`
+	WangID [8]uint8 `json:"wangid"`
+}
+
+// parseWangID reads the comma separated form TMX files use
+func parseWangID(text string) ([8]uint8, error) {
+	var wangID [8]uint8
+	fields := strings.Split(text, ",")
+	if len(fields) != len(wangID) {
+		return wangID, fmt.Errorf("wang id %q does not have 8 values", text)
+	}
+	for i, field := range fields {
+		value, err := strconv.ParseUint(strings.TrimSpace(field), 10, 8)
+		if err != nil {
+			return wangID, fmt.Errorf("wang id %q: %w", text, err)
+		}
+		wangID[i] = uint8(value)
+	}
+	return wangID, nil
+}
+
+func formatWangID(wangID [8]uint8) string {
+	fields := make([]string, len(wangID))
+	for i, value := range wangID {
+		fields[i] = strconv.Itoa(int(value))
+	}
+	return strings.Join(fields, ",")
+}
+
+// tilesetTerrains returns a terrain for every colour of a tileset's Wang sets.
+// Tiles mixing a colour with another one are left out of both
+func tilesetTerrains(tileset *tiledTileset) []*Terrain {
+	var terrains []*Terrain
+	for _, set := range tileset.WangSets {
+		for c, colour := range set.Colors {
+			terrain := &Terrain{Name: colour.Name}
+			index := uint8(c + 1)
+
+			for _, wangTile := range set.WangTiles {
+				tile := terrainTile{id: wangTile.TileID}
+				mixed := false
+				for i, value := range wangTile.WangID {
+					mixed = mixed || (value != 0 && value != index)
+					tile.sides[i] = value == index
+				}
+				if mixed || tile.sides == [8]bool{} {
+					continue
+				}
+				terrain.tiles = append(terrain.tiles, tile)
+			}
+
+			// Edge sets only describe sides and corner sets only corners
+			for i := range terrain.used {
+				corner := i%2 == 1
+				terrain.used[i] = set.Type == "mixed" || (set.Type == "corner") == corner
+			}
+			terrains = append(terrains, terrain)
+		}
+	}
+	return terrains
+}
+
+// readTerrain reads a TSX or JSON tileset and the terrain with a name from it
+func readTerrain(path, name string) (*Terrain, *tiledTileset, error) {
+	tileset := &tiledTileset{FirstGID: 1, Source: filepath.Base(path)}
+	if err := resolveTileset(tileset, filepath.Dir(path)); err != nil {
+		return nil, nil, err
+	}
+	for _, terrain := range tilesetTerrains(tileset) {
+		if terrain.Name == name {
+			return terrain, tileset, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("tileset %s has no terrain %q", path, name)
+}
+
+// mapTerrains finds the terrain of every global ID of a map's tilesets
+type mapTerrains map[uint32]mapTerrain
+
+type mapTerrain struct {
+	terrain  *Terrain
+	firstGID uint32
+}
+
+func newMapTerrains(tilesets []tiledTileset) mapTerrains {
+	terrains := mapTerrains{}
+	for i := range tilesets {
+		for _, terrain := range tilesetTerrains(&tilesets[i]) {
+			for _, tile := range terrain.tiles {
+				gid := tilesets[i].FirstGID + tile.id
+				if _, ok := terrains[gid]; !ok {
+					terrains[gid] = mapTerrain{terrain: terrain, firstGID: tilesets[i].FirstGID}
+				}
+			}
+		}
+	}
+	return terrains
+}
+
+// autotileMap picks the tiles of every terrain in the map's tile layers, apart
+// from maps and layers with a false "autotile" property
+func autotileMap(data *tiledMap) {
+	if value, ok := convertProperties(data.Properties)["autotile"]; ok && value == "false" {
+		return
+	}
+
+	terrains := newMapTerrains(data.Tilesets)
+	if len(terrains) == 0 {
+		return
+	}
+	for _, layer := range collectLayers(data.Layers, "tilelayer", nil) {
+		if value, ok := convertProperties(layer.Properties)["autotile"]; ok && value == "false" {
+			continue
+		}
+		terrains.autotile(layer, 0, 0, layer.Width-1, layer.Height-1)
+	}
+}
+
+// autotile picks the tiles of every terrain cell between two corners of a
+// layer, inclusive. Cells outside the layer count as every terrain, so ground
+// running into the edge of the map doesn't get a border there
+func (terrains mapTerrains) autotile(layer *tiledLayer, x0, y0, x1, y1 int) {
+	if len(layer.gids) != layer.Width*layer.Height {
+		return
+	}
+
+	terrainAt := func(x, y int) (mapTerrain, bool) {
+		cell, ok := terrains[layer.gids[y*layer.Width+x]&gidMask]
+		return cell, ok
+	}
+
+	for y := max(y0, 0); y <= min(y1, layer.Height-1); y++ {
+		for x := max(x0, 0); x <= min(x1, layer.Width-1); x++ {
+			cell, ok := terrainAt(x, y)
+			if !ok {
+				continue
+			}
+
+			neighbours := NeighboursOf(x, y, func(nx, ny int) bool {
+				if nx < 0 || ny < 0 || nx >= layer.Width || ny >= layer.Height {
+					return true
+				}
+				other, ok := terrainAt(nx, ny)
+				return ok && other.terrain == cell.terrain
+			})
+
+			gid := layer.gids[y*layer.Width+x]
+			current := gid&gidMask - cell.firstGID
+			if !cell.terrain.Fits(current, neighbours) {
+				layer.gids[y*layer.Width+x] = cell.firstGID + cell.terrain.Choose(neighbours)
+			}
+		}
+	}
+}
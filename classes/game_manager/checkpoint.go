@@ -0,0 +1,84 @@
+package game_manager
+
+import (
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// CheckpointClass is the class of the objects in a map that become checkpoints,
+// props with a "checkpoint" property, like the Pointer signs, are checkpoints too
+const CheckpointClass = "checkpoint"
+
+// Checkpoint is somewhere the player comes back to after dying, once they have
+// touched it. Spawn is where the player reappears, standing on the ground the
+// checkpoint stands on. With ResetEnemies set, respawning there also puts back
+// the enemies between it and the next checkpoint along
+type Checkpoint struct {
+	Area         rl.Rectangle
+	Spawn        rl.Vector2
+	ResetEnemies bool
+	Prop         *Prop
+}
+
+// NewCheckpoint creates a checkpoint whose bottom edge is the ground, touched
+// anywhere in a character's height above it
+func NewCheckpoint(area rl.Rectangle) *Checkpoint {
+	bottom := area.Y + area.Height
+	centre := area.X + area.Width/2
+	return &Checkpoint{
+		Area:  rl.NewRectangle(area.X, min(area.Y, bottom-characterSize), area.Width, max(area.Height, characterSize)),
+		Spawn: rl.Vector2{X: centre - characterSize/2, Y: bottom - characterSize},
+	}
+}
+
+// Checkpoints returns a checkpoint for every checkpoint object and prop in the
+// map, from left to right. Their "reset_enemies" property sets ResetEnemies
+func (tileMap *TileMap) Checkpoints() []*Checkpoint {
+	var checkpoints []*Checkpoint
+	for _, object := range tileMap.ObjectsOfClass(CheckpointClass) {
+		checkpoint := NewCheckpoint(object.Bounds())
+		checkpoint.ResetEnemies = object.Properties.Bool("reset_enemies")
+		checkpoint.Prop = tileMap.Prop(object.ID)
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	for _, layer := range tileMap.Layers {
+		for _, prop := range layer.Props {
+			if !prop.Properties.Bool("checkpoint") || tileMap.isCheckpointObject(prop.ObjectID) {
+				continue
+			}
+			checkpoint := NewCheckpoint(prop.Bounds())
+			checkpoint.ResetEnemies = prop.Properties.Bool("reset_enemies")
+			checkpoint.Prop = prop
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Area.X < checkpoints[j].Area.X
+	})
+	return checkpoints
+}
+
+// isCheckpointObject reports whether the object with an ID is already a
+// checkpoint through its class
+func (tileMap *TileMap) isCheckpointObject(id int) bool {
+	object := tileMap.Object(id)
+	return object != nil && object.Class == CheckpointClass
+}
+
+// Draw marks a checkpoint without a prop of its own with a post, flagged once active
+func (c *Checkpoint) Draw(active bool) {
+	if c.Prop != nil {
+		return
+	}
+	bottom := c.Area.Y + c.Area.Height
+	x := c.Area.X + c.Area.Width/2
+	rl.DrawLineEx(rl.Vector2{X: x, Y: bottom}, rl.Vector2{X: x, Y: bottom - 40}, 3, rl.DarkGray)
+	color := rl.Gray
+	if active {
+		color = rl.Green
+	}
+	rl.DrawTriangle(rl.Vector2{X: x, Y: bottom - 40}, rl.Vector2{X: x, Y: bottom - 28}, rl.Vector2{X: x + 14, Y: bottom - 34}, color)
+}
@@ -0,0 +1,298 @@
+package game_manager
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+)
+
+// Jump is how a character jumps, Speed being its top running speed, in pixels
+// per second and pixels per second squared
+type Jump struct {
+	Speed     float32
+	Gravity   float32
+	JumpSpeed float32
+}
+
+// Rise returns how many whole tiles high a jump reaches
+func (j Jump) Rise(tileHeight int32) int {
+	if j.Gravity <= 0 || tileHeight <= 0 {
+		return 0
+	}
+	return int(j.height() / float32(tileHeight))
+}
+
+// Gap returns how many empty tiles a running jump clears, landing up tiles
+// higher than it took off. A tile is kept spare for where the feet leave and land
+func (j Jump) Gap(up int, tileWidth, tileHeight int32) int {
+	fall := j.height() - float32(up)*float32(tileHeight)
+	if j.Gravity <= 0 || tileWidth <= 0 || fall < 0 {
+		return 0
+	}
+	airtime := j.JumpSpeed/j.Gravity + float32(math.Sqrt(float64(2*fall/j.Gravity)))
+	return max(int(j.Speed*airtime/float32(tileWidth))-1, 0)
+}
+
+// height returns how far a jump rises in pixels
+func (j Jump) height() float32 {
+	return j.JumpSpeed * j.JumpSpeed / (2 * j.Gravity)
+}
+
+// characterSize is the width and height of the sprites generated levels place
+// characters for, their positions being the top left of the sprite
+const characterSize = 128
+
+// GeneratorOptions controls GenerateLevel. Chunks is how many templates are
+// laid between the start and the exit, Height is in tiles and Enemies are the
+// characters enemy pockets are filled with
+type GeneratorOptions struct {
+	Seed    int64
+	Chunks  int
+	Height  int
+	Jump    Jump
+	Enemies []string
+}
+
+// DefaultGeneratorOptions are used for anything GeneratorOptions leave out
+var DefaultGeneratorOptions = GeneratorOptions{
+	Chunks:  8,
+	Height:  16,
+	Enemies: []string{"Raider_1", "Gangsters_1"},
+}
+
+// GeneratedLevel is a level laid out by GenerateLevel. Tiles is Go level data
+// painted with levels.Ground
+type GeneratedLevel struct {
+	Tiles   [][]int
+	Spawn   levels.Point
+	Enemies []levels.EnemySpawn
+	Exit    levels.Zone
+}
+
+// Settings returns the level's settings with its tiles as their Go data, ready
+// for LoadLevel
+func (l *GeneratedLevel) Settings(number int, name, background string) *levels.Level {
+	return &levels.Level{
+		Number:     number,
+		Name:       name,
+		Background: background,
+		Spawn:      l.Spawn,
+		Enemies:    l.Enemies,
+		Exits:      []levels.Zone{l.Exit},
+		Tiles:      l.Tiles,
+	}
+}
+
+// GenerateLevel lays out a level from chunk templates: runs of ground, gaps,
+// platforms over pits and enemy pockets. Every chunk starts within a jump of
+// where the one before ends and nothing hangs over the ground, so the exit can
+// always be reached. The same options always give the same level
+func GenerateLevel(options GeneratorOptions) *GeneratedLevel {
+	if options.Chunks <= 0 {
+		options.Chunks = DefaultGeneratorOptions.Chunks
+	}
+	if options.Height <= 0 {
+		options.Height = DefaultGeneratorOptions.Height
+	}
+	if len(options.Enemies) == 0 {
+		options.Enemies = DefaultGeneratorOptions.Enemies
+	}
+
+	g := &generator{
+		random:  rand.New(rand.NewSource(options.Seed)),
+		options: options,
+		rise:    options.Jump.Rise(TileSize),
+		top:     options.Height - 7,
+		bottom:  options.Height - 2,
+		floor:   options.Height - 3,
+		level:   &GeneratedLevel{},
+	}
+
+	g.wall()
+	g.ground(g.floor, 5)
+	g.level.Spawn = levels.Point{X: 2 * TileSize, Y: float32(g.floor*TileSize - characterSize)}
+
+	for i := 0; i < options.Chunks; i++ {
+		chunkTemplates[g.random.Intn(len(chunkTemplates))](g)
+	}
+
+	g.ground(g.floor, 5)
+	g.level.Exit = levels.Zone{
+		X:      float32((len(g.columns) - 2) * TileSize),
+		Y:      float32((g.floor - 3) * TileSize),
+		Width:  2 * TileSize,
+		Height: 3 * TileSize,
+	}
+	g.wall()
+
+	g.level.Tiles = g.rows()
+	return g.level
+}
+
+// generator builds a level column by column, floor is the row the route
+// currently runs along
+type generator struct {
+	random  *rand.Rand
+	options GeneratorOptions
+	rise    int
+	top     int // the highest row the route runs along
+	bottom  int // the lowest
+	floor   int
+	columns [][]int
+	level   *GeneratedLevel
+}
+
+// chunkTemplates each continue the route from the end of the level
+var chunkTemplates = []func(g *generator){
+	(*generator).groundRun,
+	(*generator).gap,
+	(*generator).platforms,
+	(*generator).enemyPocket,
+}
+
+// groundRun steps up or down by at most a jump and runs along the ground
+func (g *generator) groundRun() {
+	g.floor = g.clampRow(g.floor - g.random.Intn(g.rise+3) + 2)
+	g.ground(g.floor, 3+g.random.Intn(4))
+}
+
+// gap leaves a pit a running jump clears, landing up to a jump higher or a little lower
+func (g *generator) gap() {
+	landing := g.clampRow(g.floor - g.random.Intn(g.rise+3) + 2)
+	width := g.gapTo(landing)
+	if width == 0 {
+		g.groundRun()
+		return
+	}
+	g.empty(1 + g.random.Intn(width))
+	g.floor = landing
+	g.ground(g.floor, 2+g.random.Intn(3))
+}
+
+// platforms lays floating platforms a jump apart over a pit, then ground to land on
+func (g *generator) platforms() {
+	if g.gapTo(g.floor) == 0 {
+		g.groundRun()
+		return
+	}
+
+	for count := 2 + g.random.Intn(2); count > 0; count-- {
+		row := g.clampRow(g.floor - g.random.Intn(2*g.rise+1) + g.rise)
+		if g.gapTo(row) == 0 {
+			row = g.floor
+		}
+		g.empty(1 + g.random.Intn(g.gapTo(row)))
+		g.floor = row
+		for width := 2 + g.random.Intn(2); width > 0; width-- {
+			column := make([]int, g.options.Height)
+			column[row] = levels.Ground
+			g.columns = append(g.columns, column)
+		}
+	}
+
+	landing := g.clampRow(g.floor - g.random.Intn(g.rise+1))
+	if g.gapTo(landing) == 0 {
+		landing = g.floor
+	}
+	g.empty(1 + g.random.Intn(g.gapTo(landing)))
+	g.floor = landing
+	g.ground(g.floor, 3)
+}
+
+// enemyPocket is a flat stretch with an enemy patrolling it, sunk a tile into
+// the ground when the player can jump back out
+func (g *generator) enemyPocket() {
+	g.ground(g.floor, 1)
+	pocket := g.floor
+	if g.rise > 0 && g.floor < g.bottom && g.random.Intn(2) == 0 {
+		pocket++
+	}
+
+	width := 7 + g.random.Intn(3)
+	start := len(g.columns)
+	g.ground(pocket, width)
+	g.ground(g.floor, 1)
+
+	centre := float32(start*TileSize) + float32(width*TileSize)/2
+	g.level.Enemies = append(g.level.Enemies, levels.EnemySpawn{
+		Character: g.options.Enemies[g.random.Intn(len(g.options.Enemies))],
+		Health:    int32(3 + g.random.Intn(3)),
+		X:         centre - characterSize/2,
+		Y:         float32(pocket*TileSize - characterSize),
+		Facing:    "left",
+		Patrol:    float32((width/2 - 1) * TileSize),
+	})
+}
+
+// gapTo returns the widest pit the player can jump from the floor to a row
+func (g *generator) gapTo(row int) int {
+	return g.options.Jump.Gap(g.floor-row, TileSize, TileSize)
+}
+
+// clampRow keeps a row within the rows the route may use
+func (g *generator) clampRow(row int) int {
+	return min(max(row, g.top), g.bottom)
+}
+
+// ground adds columns filled from a row down to the bottom of the level
+func (g *generator) ground(row, width int) {
+	for ; width > 0; width-- {
+		column := make([]int, g.options.Height)
+		for y := row; y < len(column); y++ {
+			column[y] = levels.Ground
+		}
+		g.columns = append(g.columns, column)
+	}
+}
+
+// empty adds columns with nothing in them
+func (g *generator) empty(width int) {
+	for ; width > 0; width-- {
+		g.columns = append(g.columns, make([]int, g.options.Height))
+	}
+}
+
+// wall adds a column of ground the full height of the level
+func (g *generator) wall() {
+	g.ground(0, 1)
+}
+
+// rows turns the columns into Go level data
+func (g *generator) rows() [][]int {
+	rows := make([][]int, g.options.Height)
+	for y := range rows {
+		rows[y] = make([]int, len(g.columns))
+		for x, column := range g.columns {
+			rows[y][x] = column[y]
+		}
+	}
+	return rows
+}
+
+// EndlessRegistry returns levels generated one after another for as long as
+// they keep being completed, each longer than the last
+func EndlessRegistry(options GeneratorOptions) *levels.Registry {
+	chunks := options.Chunks
+	if chunks <= 0 {
+		chunks = DefaultGeneratorOptions.Chunks
+	}
+
+	generate := func(number int) *levels.Level {
+		levelOptions := options
+		levelOptions.Seed = options.Seed + int64(number)*7919
+		levelOptions.Chunks = chunks + number - 1
+
+		background := "Day"
+		if number%2 == 0 {
+			background = "Night"
+		}
+		return GenerateLevel(levelOptions).Settings(number, fmt.Sprintf("Endless %d", number), background)
+	}
+
+	return &levels.Registry{
+		Levels:   []*levels.Level{generate(1)},
+		Generate: generate,
+	}
+}
@@ -1,39 +1,247 @@
 package game_manager
 
 import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/grcatterall/go-game/classes/assets"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// TileSize is the width and height of a tile in the built in levels
+const TileSize = 32
+
 type TileMap struct {
-	Tiles [][]*Tile
+	Tiles        *TileGrid // the layer characters collide with
+	Layers       []*Layer
+	ObjectLayers []*ObjectLayer
+	Properties   Properties
+	TileWidth    int32
+	TileHeight   int32
+	textures     []*assets.TextureHandle
+}
+
+// Layer is a grid of tiles or a set of props drawn together. Layers draw back to
+// front in map order, behind the characters unless Foreground is set
+type Layer struct {
+	Name       string
+	Tiles      *TileGrid
+	Props      []*Prop
+	Visible    bool
+	Opacity    float32
+	Foreground bool
+	Properties Properties
+}
+
+// LoadLevel loads a level's map along with the entities it places. Levels
+// without a working Tiled map use their Go data
+func LoadLevel(settings *levels.Level) (*TileMap, []Spawn) {
+	tileMap := loadLevelMap(settings)
+	return tileMap, LevelSpawns(settings, tileMap)
 }
 
-func LoadLevel(level [][]int, tileTextures map[int]rl.Texture2D) *TileMap {
-	var tileMap TileMap
+// LevelSpawns returns everything placed in a level's map followed by the level's
+// own enemies and triggers, with the level's spawn used when the map places no player
+func LevelSpawns(settings *levels.Level, tileMap *TileMap) []Spawn {
+	spawns := tileMap.Spawns()
+
+	if len(SpawnsOfKind(spawns, SpawnPlayer)) == 0 {
+		spawns = append([]Spawn{{
+			Kind:     SpawnPlayer,
+			Position: rl.Vector2{X: settings.Spawn.X, Y: settings.Spawn.Y},
+		}}, spawns...)
+	}
+
+	for _, enemy := range settings.Enemies {
+		spawns = append(spawns, Spawn{
+			Kind:        SpawnEnemy,
+			Archetype:   enemy.Character,
+			Position:    rl.Vector2{X: enemy.X, Y: enemy.Y},
+			Health:      enemy.Health,
+			FacingLeft:  enemy.Facing == "left",
+			PatrolRange: enemy.Patrol,
+			Wave:        enemy.Wave,
+		})
+	}
+
+	for _, trigger := range settings.Triggers {
+		spawns = append(spawns, Spawn{
+			Kind:     SpawnTrigger,
+			Name:     trigger.Name,
+			Position: rl.Vector2{X: trigger.X, Y: trigger.Y},
+			Area:     rl.NewRectangle(trigger.X, trigger.Y, trigger.Width, trigger.Height),
+			Properties: Properties{
+				"on":      trigger.On,
+				"delay":   strconv.FormatFloat(float64(trigger.Delay), 'g', -1, 32),
+				"repeat":  strconv.FormatBool(trigger.Repeat),
+				"actions": strings.Join(trigger.Actions, "\n"),
+			},
+		})
+	}
+
+	return spawns
+}
+
+// loadLevelMap loads a level's Tiled map, falling back to its Go data when it
+// has no map or the map is broken
+func loadLevelMap(settings *levels.Level) *TileMap {
+	if path := settings.MapPath(); path != "" {
+		tileMap, err := LoadTiledMap(path)
+		if err == nil {
+			return tileMap
+		}
+		log.Printf("level %d: %v", settings.Number, err)
+	}
+
+	tileTextures := LoadTileTextures()
+	tileMap := LoadGoLevel(settings.GoData(), tileTextures)
+	for _, texture := range tileTextures {
+		tileMap.textures = append(tileMap.textures, texture)
+	}
+	return tileMap
+}
+
+// GroundTileset holds the "ground" terrain that levels.Ground cells of Go level data are painted with
+const GroundTileset = "assets/levels/tileset.tsx"
+
+// LoadGoLevel builds a map from Go level data, where every tile is a solid
+// block. Cells holding levels.Ground get the ground terrain tile that joins up
+// with the ground around them
+func LoadGoLevel(level [][]int, tileTextures map[int]*assets.TextureHandle) *TileMap {
+	width := 0
+	for _, row := range level {
+		width = max(width, len(row))
+	}
+
+	tileMap := &TileMap{
+		Tiles:      NewTileGrid(width, len(level), TileSize, TileSize),
+		TileWidth:  TileSize,
+		TileHeight: TileSize,
+	}
+
+	kinds := map[int]*Tile{}
+	var ground *goGround
 	for y, row := range level {
-		var tileRow []*Tile
 		for x, tileID := range row {
-			if tileID != 0 {
-				tile := &Tile{
-					Texture:  tileTextures[tileID],
-					Position: rl.Vector2{X: float32(x * 32), Y: float32(y * 32)},
+			switch {
+			case tileID == levels.Ground:
+				if ground == nil {
+					ground = loadGoGround(tileMap)
+				}
+				tileMap.Tiles.Set(x, y, ground.tile(level, x, y))
+			case tileID != 0:
+				kind, ok := kinds[tileID]
+				if !ok {
+					kind = &Tile{Definition: DefaultTileDefinition}
+					if handle, ok := tileTextures[tileID]; ok {
+						kind.Texture = handle.Texture
+					}
+					kinds[tileID] = kind
 				}
-				tileRow = append(tileRow, tile)
-			} else {
-				tileRow = append(tileRow, nil)
+				tileMap.Tiles.Set(x, y, kind)
 			}
 		}
-		tileMap.Tiles = append(tileMap.Tiles, tileRow)
 	}
-	return &tileMap
+
+	tileMap.Layers = []*Layer{{Name: "level", Tiles: tileMap.Tiles, Visible: true, Opacity: 1}}
+	return tileMap
+}
+
+// goGround picks and loads the ground tiles of Go level data
+type goGround struct {
+	terrain *Terrain
+	builder *tiledBuilder
+}
+
+// loadGoGround reads the ground terrain, the map releases its texture when unloaded
+func loadGoGround(tileMap *TileMap) *goGround {
+	terrain, tileset, err := readTerrain(GroundTileset, "ground")
+	if err != nil {
+		log.Printf("ground tiles: %v", err)
+		return &goGround{}
+	}
+
+	builder := newTiledBuilder(&tiledMap{TileWidth: TileSize, TileHeight: TileSize, Tilesets: []tiledTileset{*tileset}})
+	builder.tileMap = tileMap
+	return &goGround{terrain: terrain, builder: builder}
+}
+
+// tile returns the ground tile for a cell, nil when the ground tileset couldn't be read.
+// Cells off the edge of the level count as ground
+func (g *goGround) tile(level [][]int, x, y int) *Tile {
+	if g.terrain == nil {
+		return nil
+	}
+
+	neighbours := NeighboursOf(x, y, func(nx, ny int) bool {
+		if ny < 0 || ny >= len(level) || nx < 0 || nx >= len(level[ny]) {
+			return true
+		}
+		return level[ny][nx] == levels.Ground
+	})
+
+	kind, err := g.builder.kind(1 + g.terrain.Choose(neighbours))
+	if err != nil {
+		log.Printf("ground tiles: %v", err)
+		return nil
+	}
+	return kind
+}
+
+// DrawBackground renders the layers behind the characters, skipping tiles and
+// props outside the view
+func (tileMap *TileMap) DrawBackground(view rl.Rectangle) {
+	tileMap.drawLayers(view, false)
 }
 
-func (tileMap *TileMap) Draw() {
-	for _, row := range tileMap.Tiles {
-		for _, tile := range row {
-			if tile != nil {
-				rl.DrawTextureV(tile.Texture, tile.Position, rl.White)
+// DrawForeground renders the layers drawn over the characters, skipping tiles
+// and props outside the view
+func (tileMap *TileMap) DrawForeground(view rl.Rectangle) {
+	tileMap.drawLayers(view, true)
+}
+
+func (tileMap *TileMap) drawLayers(view rl.Rectangle, foreground bool) {
+	for _, layer := range tileMap.Layers {
+		if !layer.Visible || layer.Foreground != foreground {
+			continue
+		}
+
+		tint := rl.Fade(rl.White, layer.Opacity)
+		layer.Tiles.Draw(view, tint)
+		for _, prop := range layer.Props {
+			if !prop.Hidden && rl.CheckCollisionRecs(prop.Bounds(), view) {
+				prop.Draw(tint)
 			}
 		}
 	}
 }
+
+// Bake draws the tiles in view into a texture per chunk, ahead of drawing
+// them. It's optional, call it before BeginMode2D
+func (tileMap *TileMap) Bake(view rl.Rectangle) {
+	for _, layer := range tileMap.Layers {
+		if layer.Visible {
+			layer.Tiles.Bake(view)
+		}
+	}
+}
+
+// Height returns the height of the map in pixels
+func (tileMap *TileMap) Height() float32 {
+	return tileMap.Tiles.PixelHeight()
+}
+
+// Unload releases the tileset textures the map loaded itself and any baked
+// chunks, levels built by LoadGoLevel leave the textures they were given to the caller
+func (tileMap *TileMap) Unload() {
+	for _, layer := range tileMap.Layers {
+		layer.Tiles.Unload()
+	}
+	for _, texture := range tileMap.textures {
+		texture.Release()
+	}
+	tileMap.textures = nil
+}
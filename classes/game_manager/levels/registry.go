@@ -0,0 +1,165 @@
+package levels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// mapsDir is where levels authored in the Tiled editor are saved
+const mapsDir = "assets/levels"
+
+// RegistryFile lists every level in the order they are played
+const RegistryFile = "assets/levels/levels.json"
+
+// Point is a position in world coordinates
+type Point struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// Zone is an area of a level in world coordinates
+type Zone struct {
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// EnemySpawn places an enemy when a level starts, Patrol is how far either side
+// of its spawn it wanders before it sees the player. Enemies in a Wave wait
+// until a trigger spawns the wave
+type EnemySpawn struct {
+	Character string  `json:"character"`
+	Health    int32   `json:"health"`
+	X         float32 `json:"x"`
+	Y         float32 `json:"y"`
+	Facing    string  `json:"facing,omitempty"` // left or right
+	Patrol    float32 `json:"patrol,omitempty"`
+	Wave      string  `json:"wave,omitempty"`
+}
+
+// Trigger is a zone that runs its actions when the player enters it, leaves it
+// or stays in it for Delay seconds. Actions are lines like "spawn raiders",
+// "message 2 Ambush!", "open gate", "close gate", "cutscene intro" and "end"
+type Trigger struct {
+	Name string `json:"name,omitempty"`
+	Zone
+	On      string   `json:"on,omitempty"` // enter, exit or stay, enter when left out
+	Delay   float32  `json:"delay,omitempty"`
+	Repeat  bool     `json:"repeat,omitempty"`
+	Actions []string `json:"actions"`
+}
+
+// Level holds the settings of one level. Map is a Tiled map in assets/levels,
+// levels without one use their Go data. Spawn is used when the map places no
+// player, and Enemies, Exits and Triggers add to the ones placed in the map.
+// Cutscenes are the lines of each cutscene triggers play, by name. Tiles is Go
+// data made up for the level while playing, used instead of GetLevel's
+type Level struct {
+	Number     int                 `json:"number"`
+	Name       string              `json:"name"`
+	Map        string              `json:"map,omitempty"`
+	Background string              `json:"background"` // Day or Night
+	Music      string              `json:"music,omitempty"`
+	Spawn      Point               `json:"spawn"`
+	Enemies    []EnemySpawn        `json:"enemies,omitempty"`
+	Exits      []Zone              `json:"exits,omitempty"`
+	Triggers   []Trigger           `json:"triggers,omitempty"`
+	Cutscenes  map[string][]string `json:"cutscenes,omitempty"`
+	Tiles      [][]int             `json:"-"`
+}
+
+// MapPath returns the path of the level's Tiled map, empty when it has none
+func (l *Level) MapPath() string {
+	if l.Map == "" {
+		return ""
+	}
+	return filepath.Join(mapsDir, l.Map)
+}
+
+// GoData returns the level's Go level data, nil when it has none
+func (l *Level) GoData() [][]int {
+	if l.Tiles != nil {
+		return l.Tiles
+	}
+	return GetLevel(l.Number)
+}
+
+// Registry is every level in play order. Generate, when set, makes up the
+// level after the last one, so the registry never runs out
+type Registry struct {
+	Levels   []*Level                `json:"levels"`
+	Generate func(number int) *Level `json:"-"`
+}
+
+// Default is the registry the game plays through, main replaces it with the one on disk
+var Default = &Registry{
+	Levels: []*Level{{
+		Number:     1,
+		Name:       "Outskirts",
+		Background: "Day",
+		Spawn:      Point{X: 112, Y: 226},
+		Enemies:    []EnemySpawn{{Character: "Raider_1", Health: 5, X: 832, Y: 192}},
+	}},
+}
+
+// LoadRegistry reads a level registry, checking every level has a unique number
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var registry Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("parsing level registry %s: %w", path, err)
+	}
+	if len(registry.Levels) == 0 {
+		return nil, fmt.Errorf("level registry %s has no levels", path)
+	}
+
+	seen := map[int]bool{}
+	for _, level := range registry.Levels {
+		if seen[level.Number] {
+			return nil, fmt.Errorf("level registry %s: level %d is listed twice", path, level.Number)
+		}
+		seen[level.Number] = true
+	}
+
+	return &registry, nil
+}
+
+// First returns the level a new game starts on
+func (r *Registry) First() *Level {
+	return r.Levels[0]
+}
+
+// Level returns the settings of a level, or nil when there is no such level
+func (r *Registry) Level(number int) *Level {
+	for _, level := range r.Levels {
+		if level.Number == number {
+			return level
+		}
+	}
+	return nil
+}
+
+// Next returns the level played after the given one, or nil after the last
+// level of a registry that doesn't generate more
+func (r *Registry) Next(number int) *Level {
+	for i, level := range r.Levels {
+		if level.Number == number && i+1 < len(r.Levels) {
+			return r.Levels[i+1]
+		}
+	}
+
+	last := r.Levels[len(r.Levels)-1]
+	if r.Generate != nil && last.Number == number {
+		next := r.Generate(number + 1)
+		r.Levels = append(r.Levels, next)
+		return next
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package game_manager
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// MapObject is a shape placed in an object layer, like a spawn point or a trigger
+// area. Objects showing a tile image also become props. Points are the corners
+// of a polyline or polygon in world coordinates, Closed for a polygon
+type MapObject struct {
+	ID         int
+	Name       string
+	Class      string
+	X          float32
+	Y          float32
+	Width      float32
+	Height     float32
+	Point      bool
+	Points     []rl.Vector2
+	Closed     bool
+	Properties Properties
+}
+
+// Bounds returns the area the object covers in world coordinates
+func (o *MapObject) Bounds() rl.Rectangle {
+	return rl.NewRectangle(o.X, o.Y, o.Width, o.Height)
+}
+
+// Prop is an image placed anywhere in a level, like a tree or a box, scaled to
+// Width and Height. Props are only decoration and never collide, though a
+// platform can move the prop of its object about. Hidden props aren't drawn.
+// Properties are the object's, on top of the ones its tile has in the tileset
+type Prop struct {
+	ObjectID   int
+	Texture    rl.Texture2D
+	Source     rl.Rectangle
+	Position   rl.Vector2
+	Width      float32
+	Height     float32
+	FlipX      bool
+	FlipY      bool
+	Hidden     bool
+	Properties Properties
+}
+
+// Bounds returns the area the prop covers in world coordinates
+func (p *Prop) Bounds() rl.Rectangle {
+	return rl.NewRectangle(p.Position.X, p.Position.Y, p.Width, p.Height)
+}
+
+// Draw renders the prop at its position
+func (p *Prop) Draw(tint rl.Color) {
+	source := p.Source
+	if p.FlipX {
+		source.X += source.Width
+		source.Width = -source.Width
+	}
+	if p.FlipY {
+		source.Y += source.Height
+		source.Height = -source.Height
+	}
+	rl.DrawTexturePro(p.Texture, source, p.Bounds(), rl.Vector2{}, 0, tint)
+}
+
+// ObjectLayer is a named group of objects
+type ObjectLayer struct {
+	Name       string
+	Objects    []*MapObject
+	Properties Properties
+}
+
+// Object returns the object with an ID from any object layer, nil when there is none
+func (tileMap *TileMap) Object(id int) *MapObject {
+	for _, layer := range tileMap.ObjectLayers {
+		for _, object := range layer.Objects {
+			if object.ID == id {
+				return object
+			}
+		}
+	}
+	return nil
+}
+
+// Prop returns the prop showing the object with an ID, nil when there is none
+func (tileMap *TileMap) Prop(objectID int) *Prop {
+	for _, layer := range tileMap.Layers {
+		for _, prop := range layer.Props {
+			if prop.ObjectID == objectID {
+				return prop
+			}
+		}
+	}
+	return nil
+}
+
+// ObjectsOfClass returns every object in the map with the given class, across all object layers
+func (tileMap *TileMap) ObjectsOfClass(class string) []*MapObject {
+	var objects []*MapObject
+	for _, layer := range tileMap.ObjectLayers {
+		for _, object := range layer.Objects {
+			if object.Class == class {
+				objects = append(objects, object)
+			}
+		}
+	}
+	return objects
+}
@@ -1,6 +1,8 @@
 package game_manager
 
 import (
+	"github.com/grcatterall/go-game/classes/assets"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -8,26 +10,22 @@ type ParallaxLayer struct {
 	Texture   rl.Texture2D
 	Speed     float32
 	PositionX float32
+	handle    *assets.TextureHandle
 }
 
 type ParallaxBackground struct {
 	Layers []ParallaxLayer
 }
 
-var (
-	backgroundLayers []rl.Texture2D
-	parallaxFactors  []float32
-	playerPosition   rl.Vector2
-)
-
 func NewParallaxBackground(layerFiles []string, speeds []float32) *ParallaxBackground {
 	layers := make([]ParallaxLayer, len(layerFiles))
 	for i, file := range layerFiles {
-		texture := rl.LoadTexture(file)
+		handle := assets.Default.LoadTexture(file)
 		layers[i] = ParallaxLayer{
-			Texture:   texture,
+			Texture:   handle.Texture,
 			Speed:     speeds[i],
 			PositionX: 0,
+			handle:    handle,
 		}
 	}
 	return &ParallaxBackground{Layers: layers}
@@ -55,23 +53,9 @@ func (pb *ParallaxBackground) Draw() {
 	}
 }
 
-func LoadBackgrounds(playerSpeed float32) {
-	backgroundLayers = []rl.Texture2D{
-		rl.LoadTexture("assets/world/2 Background/Day/1.png"), // Farthest layer
-		rl.LoadTexture("assets/world/2 Background/Day/2.png"), // Middle layer
-		rl.LoadTexture("assets/world/2 Background/Day/3.png"), // Closest layer
-		rl.LoadTexture("assets/world/2 Background/Day/4.png"), // Closest layer
-	}
-	parallaxFactors = []float32{
-		playerSpeed,
-		0.2,
-		0.4,
-		0.8,
-	}
-}
-
-func UnloadBackgrounds() {
-	for _, texture := range backgroundLayers {
-		rl.UnloadTexture(texture)
+// Unload releases the textures of every layer
+func (pb *ParallaxBackground) Unload() {
+	for _, layer := range pb.Layers {
+		layer.handle.Release()
 	}
 }
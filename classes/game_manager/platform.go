@@ -0,0 +1,240 @@
+package game_manager
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PlatformClass is the class of the objects in a map that become platforms
+const PlatformClass = "platform"
+
+// Platform defaults, in pixels and seconds
+const (
+	platformSpeed     = 40
+	platformFallDelay = 0.5
+	platformGravity   = 600
+	platformMaxFall   = 400
+	platformShake     = 1.5 // how far a platform about to fall shakes either side
+)
+
+// Platform is a piece of level that moves, characters standing on it ride
+// along. It follows a path of waypoints, going back and forth along it or
+// round and round when Loop is set, pausing Wait seconds at each. A platform
+// that Falls drops FallDelay seconds after the player first stands on it and
+// comes back Respawn seconds after dropping out of the map, never when 0.
+// Characters only land on platforms from above, like one-way tiles
+type Platform struct {
+	Area      rl.Rectangle // where the platform is now
+	Delta     rl.Vector2   // how far it moved in the last Update
+	Path      []rl.Vector2 // the top left corner of each waypoint
+	Speed     float32      // pixels per second along the path
+	Loop      bool
+	Wait      float32
+	Falls     bool
+	FallDelay float32
+	Respawn   float32
+	Friction  float32
+	Prop      *Prop // the image of the platform's object, moved along with it
+	start     rl.Vector2
+	bottom    float32 // how far down a falling platform goes before it is gone
+	target    int     // the waypoint being headed for
+	step      int     // +1 heading along the path, -1 heading back
+	waitTimer float32
+	fallTimer float32
+	triggered bool
+	falling   bool
+	fallSpeed float32
+	gone      bool
+	goneTimer float32
+}
+
+// NewPlatform creates a platform covering an area, at rest until given a path or told to fall
+func NewPlatform(area rl.Rectangle) *Platform {
+	return &Platform{
+		Area:      area,
+		Speed:     platformSpeed,
+		FallDelay: platformFallDelay,
+		Friction:  1,
+		start:     rl.Vector2{X: area.X, Y: area.Y},
+		bottom:    area.Y + platformMaxFall,
+		step:      1,
+	}
+}
+
+// Platforms returns a platform for every object of PlatformClass in the map.
+// Its "path" property names a polyline or polygon object, a polygon looping,
+// whose first corner is where the platform starts. "speed", "loop", "wait",
+// "falls", "fall_delay", "respawn" and "friction" set the rest
+func (tileMap *TileMap) Platforms() []*Platform {
+	var platforms []*Platform
+	for _, object := range tileMap.ObjectsOfClass(PlatformClass) {
+		platform := NewPlatform(object.Bounds())
+		properties := object.Properties
+
+		platform.Speed = properties.Float("speed", platform.Speed)
+		platform.Wait = properties.Float("wait", platform.Wait)
+		platform.Falls = properties.Bool("falls")
+		platform.FallDelay = properties.Float("fall_delay", platform.FallDelay)
+		platform.Respawn = properties.Float("respawn", platform.Respawn)
+		platform.Friction = properties.Float("friction", platform.Friction)
+		platform.Prop = tileMap.Prop(object.ID)
+		platform.bottom = tileMap.Height() + object.Height
+
+		if path := tileMap.Object(int(properties.Float("path", 0))); path != nil && len(path.Points) > 1 {
+			platform.Loop = path.Closed
+			offset := rl.Vector2Subtract(platform.start, path.Points[0])
+			for _, point := range path.Points {
+				platform.Path = append(platform.Path, rl.Vector2Add(point, offset))
+			}
+		}
+		if _, ok := properties["loop"]; ok {
+			platform.Loop = properties.Bool("loop")
+		}
+
+		platforms = append(platforms, platform)
+	}
+	return platforms
+}
+
+// Solid reports whether characters can stand on the platform, it isn't while it's gone
+func (p *Platform) Solid() bool {
+	return !p.gone
+}
+
+// StandOn tells a falling platform the player is standing on it
+func (p *Platform) StandOn() {
+	if p.Falls {
+		p.triggered = true
+	}
+}
+
+// Update moves the platform on by dt seconds, recording the move in Delta
+func (p *Platform) Update(dt float32) {
+	previous := rl.Vector2{X: p.Area.X, Y: p.Area.Y}
+	position := previous
+
+	switch {
+	case p.gone:
+		p.goneTimer += dt
+		if p.Respawn > 0 && p.goneTimer >= p.Respawn {
+			p.Reset()
+			previous = p.start
+			position = p.start
+		}
+	case p.falling:
+		p.fallSpeed = min(p.fallSpeed+platformGravity*dt, platformMaxFall)
+		position.Y += p.fallSpeed * dt
+		if position.Y > p.bottom {
+			p.gone = true
+			p.goneTimer = 0
+		}
+	case p.triggered:
+		p.fallTimer += dt
+		if p.fallTimer >= p.FallDelay {
+			p.falling = true
+		}
+	default:
+		position = p.followPath(position, dt)
+	}
+
+	p.Area.X, p.Area.Y = position.X, position.Y
+	p.Delta = rl.Vector2Subtract(position, previous)
+
+	if p.Prop != nil {
+		p.Prop.Position = position
+		// A platform about to fall shakes, though what stands on it doesn't
+		if p.triggered && !p.falling && int(p.fallTimer*30)%2 == 0 {
+			p.Prop.Position.X += platformShake
+		}
+		p.Prop.Hidden = p.gone
+	}
+}
+
+// followPath moves a position towards the next waypoint, turning round or
+// looping at the end of the path
+func (p *Platform) followPath(position rl.Vector2, dt float32) rl.Vector2 {
+	if len(p.Path) < 2 {
+		return position
+	}
+	if p.waitTimer > 0 {
+		p.waitTimer -= dt
+		return position
+	}
+
+	distance := p.Speed * dt
+	for distance > 0 {
+		target := p.Path[p.target]
+		remaining := rl.Vector2Distance(position, target)
+		if remaining > distance {
+			return rl.Vector2MoveTowards(position, target, distance)
+		}
+
+		position = target
+		distance -= remaining
+		p.nextWaypoint()
+		if p.Wait > 0 {
+			p.waitTimer = p.Wait
+			return position
+		}
+	}
+	return position
+}
+
+// nextWaypoint picks the waypoint after the one just reached
+func (p *Platform) nextWaypoint() {
+	next := p.target + p.step
+	switch {
+	case next >= 0 && next < len(p.Path):
+		p.target = next
+	case p.Loop:
+		p.target = 0
+	default:
+		p.step = -p.step
+		p.target += p.step
+	}
+}
+
+// Reset puts the platform back where it started, whole and still
+func (p *Platform) Reset() {
+	p.Area.X, p.Area.Y = p.start.X, p.start.Y
+	p.Delta = rl.Vector2{}
+	p.target, p.step = 0, 1
+	p.waitTimer, p.fallTimer, p.fallSpeed, p.goneTimer = 0, 0, 0, 0
+	p.triggered, p.falling, p.gone = false, false, false
+}
+
+// Supports reports whether an area's bottom edge rests on top of the platform
+func (p *Platform) Supports(area rl.Rectangle) bool {
+	if p.gone {
+		return false
+	}
+	bottom := area.Y + area.Height
+	return bottom >= p.Area.Y-platformRestTolerance && bottom <= p.Area.Y+platformRestTolerance &&
+		area.X < p.Area.X+p.Area.Width && area.X+area.Width > p.Area.X
+}
+
+// platformRestTolerance is how far from a platform's top something can be and still be resting on it
+const platformRestTolerance = 2
+
+// Draw renders platforms without a prop of their own as a plain block
+func (p *Platform) Draw() {
+	if p.gone || p.Prop != nil {
+		return
+	}
+	area := p.Area
+	if p.triggered && !p.falling && int(p.fallTimer*30)%2 == 0 {
+		area.X += platformShake
+	}
+	rl.DrawRectangleRec(area, rl.Brown)
+	rl.DrawRectangleLinesEx(area, 1, rl.DarkBrown)
+}
+
+// DrawDebug outlines the platform and the path it follows
+func (p *Platform) DrawDebug() {
+	for i := 1; i < len(p.Path); i++ {
+		rl.DrawLineV(p.Path[i-1], p.Path[i], rl.Orange)
+	}
+	if p.Loop && len(p.Path) > 2 {
+		rl.DrawLineV(p.Path[len(p.Path)-1], p.Path[0], rl.Orange)
+	}
+	rl.DrawRectangleLinesEx(p.Area, 2, rl.Orange)
+}
@@ -0,0 +1,43 @@
+package game_manager
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Properties are the custom properties set on a map, layer, object or tile in Tiled,
+// kept as text and converted when read
+type Properties map[string]string
+
+// Bool reads a true/false property, false when it isn't set
+func (p Properties) Bool(name string) bool {
+	value, _ := strconv.ParseBool(p[name])
+	return value
+}
+
+// Float reads a number property, fallback when it isn't set or isn't a number
+func (p Properties) Float(name string, fallback float32) float32 {
+	value, err := strconv.ParseFloat(p[name], 32)
+	if err != nil {
+		return fallback
+	}
+	return float32(value)
+}
+
+// String reads a text property, fallback when it isn't set
+func (p Properties) String(name string, fallback string) string {
+	if value, ok := p[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+// Names returns the names of every property, sorted
+func (p Properties) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
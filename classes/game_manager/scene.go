@@ -0,0 +1,94 @@
+package game_manager
+
+// Scene is a single screen of the game (title, gameplay, pause...) that owns
+// the resources it loads in Enter and frees in Exit
+type Scene interface {
+	Enter(manager *SceneManager)
+	Exit()
+	Update(dt float32)
+	Draw(alpha float32)
+}
+
+// SceneManager keeps a stack of scenes, only the top scene is updated but
+// every scene is drawn bottom to top so overlays sit above the scene they cover
+type SceneManager struct {
+	scenes  []Scene
+	pending []func()
+	quit    bool
+}
+
+// NewSceneManager creates a scene manager starting on the given scene
+func NewSceneManager(initial Scene) *SceneManager {
+	manager := &SceneManager{}
+	manager.push(initial)
+	return manager
+}
+
+// Push places a scene on top of the stack once the current update finishes
+func (sm *SceneManager) Push(scene Scene) {
+	sm.pending = append(sm.pending, func() { sm.push(scene) })
+}
+
+// Pop removes the top scene once the current update finishes
+func (sm *SceneManager) Pop() {
+	sm.pending = append(sm.pending, sm.pop)
+}
+
+// Replace swaps the top scene for another once the current update finishes
+func (sm *SceneManager) Replace(scene Scene) {
+	sm.pending = append(sm.pending, func() {
+		sm.pop()
+		sm.push(scene)
+	})
+}
+
+// Quit asks the game loop to stop
+func (sm *SceneManager) Quit() {
+	sm.quit = true
+}
+
+// ShouldQuit reports whether Quit was called or there are no scenes left to run
+func (sm *SceneManager) ShouldQuit() bool {
+	return sm.quit || len(sm.scenes) == 0
+}
+
+// Update steps the top scene by dt seconds and then applies any stack changes it requested
+func (sm *SceneManager) Update(dt float32) {
+	if len(sm.scenes) > 0 {
+		sm.scenes[len(sm.scenes)-1].Update(dt)
+	}
+
+	pending := sm.pending
+	sm.pending = nil
+	for _, change := range pending {
+		change()
+	}
+}
+
+// Draw renders every scene on the stack from the bottom up
+func (sm *SceneManager) Draw(alpha float32) {
+	for _, scene := range sm.scenes {
+		scene.Draw(alpha)
+	}
+}
+
+// Unload exits every scene on the stack, releasing their resources
+func (sm *SceneManager) Unload() {
+	for len(sm.scenes) > 0 {
+		sm.pop()
+	}
+}
+
+func (sm *SceneManager) push(scene Scene) {
+	sm.scenes = append(sm.scenes, scene)
+	scene.Enter(sm)
+}
+
+func (sm *SceneManager) pop() {
+	if len(sm.scenes) == 0 {
+		return
+	}
+	top := sm.scenes[len(sm.scenes)-1]
+	sm.scenes = sm.scenes[:len(sm.scenes)-1]
+	top.Exit()
+}
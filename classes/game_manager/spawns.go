@@ -0,0 +1,76 @@
+package game_manager
+
+import (
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// SpawnKind is the sort of entity a spawn places
+type SpawnKind string
+
+// The kinds of entity a level can place, matching the class of their objects in Tiled
+const (
+	SpawnPlayer  SpawnKind = "player"
+	SpawnEnemy   SpawnKind = "enemy"
+	SpawnPickup  SpawnKind = "pickup"
+	SpawnTrigger SpawnKind = "trigger"
+)
+
+// Spawn is an entity a level places when it starts. Archetype is the character
+// of an enemy or the type of a pickup, Area is the zone a trigger covers. An
+// enemy with a Wave waits for a trigger to spawn its wave
+type Spawn struct {
+	Kind        SpawnKind
+	Name        string
+	Archetype   string
+	Position    rl.Vector2
+	Area        rl.Rectangle
+	Health      int32
+	FacingLeft  bool
+	PatrolRange float32 // how far either side of its spawn an enemy wanders, 0 to stand still
+	Wave        string
+	Properties  Properties
+}
+
+// Spawns returns the entities placed by the objects in the map. The class of an
+// object picks the kind, its archetype comes from an "archetype" property or its
+// name, and "health", "facing" (left or right), "patrol" and "wave" set the rest
+func (tileMap *TileMap) Spawns() []Spawn {
+	var spawns []Spawn
+	for _, layer := range tileMap.ObjectLayers {
+		for _, object := range layer.Objects {
+			kind := SpawnKind(object.Class)
+			switch kind {
+			case SpawnPlayer, SpawnEnemy, SpawnPickup, SpawnTrigger:
+			default:
+				continue
+			}
+
+			spawns = append(spawns, Spawn{
+				Kind:        kind,
+				Name:        object.Name,
+				Archetype:   object.Properties.String("archetype", object.Name),
+				Position:    rl.Vector2{X: object.X, Y: object.Y},
+				Area:        object.Bounds(),
+				Health:      int32(object.Properties.Float("health", 0)),
+				FacingLeft:  strings.EqualFold(object.Properties.String("facing", ""), "left"),
+				PatrolRange: object.Properties.Float("patrol", 0),
+				Wave:        object.Properties.String("wave", ""),
+				Properties:  object.Properties,
+			})
+		}
+	}
+	return spawns
+}
+
+// SpawnsOfKind returns the spawns of one kind, in the order they were declared
+func SpawnsOfKind(spawns []Spawn, kind SpawnKind) []Spawn {
+	var matching []Spawn
+	for _, spawn := range spawns {
+		if spawn.Kind == kind {
+			matching = append(matching, spawn)
+		}
+	}
+	return matching
+}
@@ -1,14 +1,82 @@
 package game_manager
 
 import (
+	"github.com/grcatterall/go-game/classes/assets"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Tile is a kind of tile, shared by every cell of a TileGrid that holds it
 type Tile struct {
-	Texture  rl.Texture2D
-	Position rl.Vector2
+	Texture    rl.Texture2D
+	Source     rl.Rectangle // the tile's part of the texture, all of it when empty
+	FlipX      bool
+	FlipY      bool
+	Properties Properties // custom properties from the tileset
+	Definition TileDefinition
+}
+
+// Draw renders the tile with its top left corner at a position
+func (t *Tile) Draw(position rl.Vector2, tint rl.Color) {
+	source := t.Source
+	if source.Width == 0 || source.Height == 0 {
+		source = rl.Rectangle{Width: float32(t.Texture.Width), Height: float32(t.Texture.Height)}
+	}
+	if t.FlipX {
+		source.X += source.Width
+		source.Width = -source.Width
+	}
+	if t.FlipY {
+		source.Y += source.Height
+		source.Height = -source.Height
+	}
+	rl.DrawTextureRec(t.Texture, source, position, tint)
+}
+
+// height returns how tall the tile is drawn, a grid cell when it has no size of its own
+func (t *Tile) height(cellHeight int32) float32 {
+	if t.Source.Height > 0 {
+		return t.Source.Height
+	}
+	if t.Texture.Height > 0 {
+		return float32(t.Texture.Height)
+	}
+	return float32(cellHeight)
+}
+
+// LoadTile loads a tile texture through the shared asset manager
+func LoadTile(filepath string) *assets.TextureHandle {
+	return assets.Default.LoadTexture(filepath)
+}
+
+// TileTexturePaths are the images for every tile ID used by the Go levels
+var TileTexturePaths = map[int]string{
+	1:  "assets/world/1 Tiles/Tile_01.png",
+	2:  "assets/world/1 Tiles/Tile_02.png",
+	3:  "assets/world/1 Tiles/Tile_40.png",
+	4:  "assets/world/1 Tiles/Tile_39.png",
+	5:  "assets/world/1 Tiles/Tile_42.png",
+	6:  "assets/world/1 Tiles/Tile_43.png",
+	7:  "assets/world/1 Tiles/Tile_37.png",
+	8:  "assets/world/1 Tiles/Tile_38.png",
+	9:  "assets/world/1 Tiles/Tile_31.png",
+	10: "assets/world/1 Tiles/Tile_30.png",
+	11: "assets/world/1 Tiles/Tile_29.png",
+	12: "assets/world/1 Tiles/Tile_28.png",
+}
+
+// LoadTileTextures loads the textures for every tile ID used by the levels
+func LoadTileTextures() map[int]*assets.TextureHandle {
+	textures := map[int]*assets.TextureHandle{}
+	for id, path := range TileTexturePaths {
+		textures[id] = LoadTile(path)
+	}
+	return textures
 }
 
-func LoadTile(filepath string) rl.Texture2D {
-	return rl.LoadTexture(filepath)
+// UnloadTileTextures releases textures loaded by LoadTileTextures
+func UnloadTileTextures(tileTextures map[int]*assets.TextureHandle) {
+	for _, texture := range tileTextures {
+		texture.Release()
+	}
 }
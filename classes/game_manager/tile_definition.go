@@ -0,0 +1,116 @@
+package game_manager
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Shape is how a tile collides with characters
+type Shape int
+
+const (
+	ShapeSolid            Shape = iota // blocks from every side
+	ShapeNone                          // decoration, never collides
+	ShapeOneWay                        // only catches things landing on it from above
+	ShapeSlopeUpRight                  // 45° ramp rising to the right
+	ShapeSlopeUpLeft                   // 45° ramp rising to the left
+	ShapeSlopeUpRightLow               // lower half of a 22.5° ramp rising to the right
+	ShapeSlopeUpRightHigh              // upper half of a 22.5° ramp rising to the right
+	ShapeSlopeUpLeftLow                // lower half of a 22.5° ramp rising to the left
+	ShapeSlopeUpLeftHigh               // upper half of a 22.5° ramp rising to the left
+)
+
+var shapeNames = map[string]Shape{
+	"solid":            ShapeSolid,
+	"none":             ShapeNone,
+	"oneway":           ShapeOneWay,
+	"slope_right":      ShapeSlopeUpRight,
+	"slope_left":       ShapeSlopeUpLeft,
+	"slope_right_low":  ShapeSlopeUpRightLow,
+	"slope_right_high": ShapeSlopeUpRightHigh,
+	"slope_left_low":   ShapeSlopeUpLeftLow,
+	"slope_left_high":  ShapeSlopeUpLeftHigh,
+}
+
+// ParseShape reads a shape from the name used in tile properties
+func ParseShape(name string) (Shape, error) {
+	if shape, ok := shapeNames[name]; ok {
+		return shape, nil
+	}
+	return ShapeSolid, fmt.Errorf("unknown tile shape %q", name)
+}
+
+// IsSlope reports whether the shape is one of the ramps
+func (s Shape) IsSlope() bool {
+	return s >= ShapeSlopeUpRight && s <= ShapeSlopeUpLeftHigh
+}
+
+// TileDefinition is how every tile with the same ID behaves. Friction is how
+// much grip the surface gives, 1 for full grip down to 0 for none, and Damage is
+// taken by the player for touching the tile
+type TileDefinition struct {
+	Shape    Shape
+	Friction float32
+	Damage   int32
+}
+
+// DefaultTileDefinition is used for tiles that don't declare anything: a solid block with full grip
+var DefaultTileDefinition = TileDefinition{Shape: ShapeSolid, Friction: 1}
+
+// ParseTileDefinition reads a definition from a tile's "shape", "friction" and
+// "damage" properties, anything left out keeps its default
+func ParseTileDefinition(properties Properties) (TileDefinition, error) {
+	definition := DefaultTileDefinition
+
+	if name, ok := properties["shape"]; ok {
+		shape, err := ParseShape(name)
+		if err != nil {
+			return definition, err
+		}
+		definition.Shape = shape
+	}
+
+	definition.Friction = properties.Float("friction", definition.Friction)
+
+	if value, ok := properties["damage"]; ok {
+		damage, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return definition, fmt.Errorf("tile damage %q is not a number", value)
+		}
+		definition.Damage = int32(damage)
+	}
+
+	return definition, nil
+}
+
+// SurfaceY returns the height of a slope's surface at world x, for a tile whose
+// top left corner is at (left, top). Tiles that aren't slopes are flat at their top
+func (d TileDefinition) SurfaceY(left, top, size, x float32) float32 {
+	along := (x - left) / size
+	if along < 0 {
+		along = 0
+	} else if along > 1 {
+		along = 1
+	}
+
+	// How far up the tile the surface is, from 0 at the bottom to 1 at the top
+	var rise float32
+	switch d.Shape {
+	case ShapeSlopeUpRight:
+		rise = along
+	case ShapeSlopeUpLeft:
+		rise = 1 - along
+	case ShapeSlopeUpRightLow:
+		rise = along / 2
+	case ShapeSlopeUpRightHigh:
+		rise = 0.5 + along/2
+	case ShapeSlopeUpLeftLow:
+		rise = (1 - along) / 2
+	case ShapeSlopeUpLeftHigh:
+		rise = 0.5 + (1-along)/2
+	default:
+		rise = 1
+	}
+
+	return top + size*(1-rise)
+}
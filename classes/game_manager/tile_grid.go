@@ -0,0 +1,291 @@
+package game_manager
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ChunkSize is the width and height of a chunk of a TileGrid in cells
+const ChunkSize = 16
+
+// bakeKeepFrames is how many frames a baked chunk is kept after it was last in view
+const bakeKeepFrames = 120
+
+// TileGrid is a layer of tiles kept in square chunks. Cells hold an index into
+// the kinds of tile the layer uses rather than a tile each, so tiles carry no
+// position of their own, and drawing skips every chunk outside the view
+type TileGrid struct {
+	Width      int // in cells
+	Height     int
+	TileWidth  int32
+	TileHeight int32
+	kinds      []*Tile // kinds[0] stands for an empty cell
+	kindIndex  map[*Tile]uint32
+	chunks     []*tileChunk // row by row, nil where a chunk has no tiles
+	chunksWide int
+	overhang   float32 // how far the tallest tile reaches above its cell
+	frame      uint64
+	baked      []*tileChunk
+}
+
+// tileChunk is ChunkSize by ChunkSize cells, optionally drawn into a texture once
+type tileChunk struct {
+	x, y     int // in chunks
+	cells    [ChunkSize * ChunkSize]uint32
+	texture  rl.RenderTexture2D
+	isBaked  bool
+	lastSeen uint64
+}
+
+// NewTileGrid creates an empty grid of width by height cells
+func NewTileGrid(width, height int, tileWidth, tileHeight int32) *TileGrid {
+	chunksWide := (width + ChunkSize - 1) / ChunkSize
+	chunksHigh := (height + ChunkSize - 1) / ChunkSize
+	return &TileGrid{
+		Width:      width,
+		Height:     height,
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		kinds:      []*Tile{nil},
+		kindIndex:  map[*Tile]uint32{},
+		chunks:     make([]*tileChunk, chunksWide*chunksHigh),
+		chunksWide: chunksWide,
+	}
+}
+
+// At returns the tile in a cell, nil for an empty cell or one outside the grid.
+// Every cell holding the same kind of tile returns the same Tile
+func (g *TileGrid) At(x, y int) *Tile {
+	if g == nil || x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return nil
+	}
+	chunk := g.chunks[y/ChunkSize*g.chunksWide+x/ChunkSize]
+	if chunk == nil {
+		return nil
+	}
+	return g.kinds[chunk.cells[y%ChunkSize*ChunkSize+x%ChunkSize]]
+}
+
+// Set places a tile in a cell, nil empties it. Tiles are shared between cells
+// rather than copied, so place the same Tile for every cell of the same kind
+func (g *TileGrid) Set(x, y int, tile *Tile) {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return
+	}
+
+	index := uint32(0)
+	if tile != nil {
+		var ok bool
+		if index, ok = g.kindIndex[tile]; !ok {
+			index = uint32(len(g.kinds))
+			g.kinds = append(g.kinds, tile)
+			g.kindIndex[tile] = index
+			g.overhang = max(g.overhang, tile.height(g.TileHeight)-float32(g.TileHeight))
+		}
+	}
+
+	c := y/ChunkSize*g.chunksWide + x/ChunkSize
+	if g.chunks[c] == nil {
+		if index == 0 {
+			return
+		}
+		g.chunks[c] = &tileChunk{x: x / ChunkSize, y: y / ChunkSize}
+	}
+	g.chunks[c].cells[y%ChunkSize*ChunkSize+x%ChunkSize] = index
+	g.unbake(g.chunks[c])
+}
+
+// CellRect returns the area a cell covers in world coordinates
+func (g *TileGrid) CellRect(x, y int) rl.Rectangle {
+	return rl.NewRectangle(float32(x)*float32(g.TileWidth), float32(y)*float32(g.TileHeight), float32(g.TileWidth), float32(g.TileHeight))
+}
+
+// TilePosition returns where a tile in a cell is drawn, tiles taller than the
+// grid sit on the bottom of their cell like in Tiled
+func (g *TileGrid) TilePosition(x, y int, tile *Tile) rl.Vector2 {
+	return rl.Vector2{
+		X: float32(x) * float32(g.TileWidth),
+		Y: float32(y+1)*float32(g.TileHeight) - tile.height(g.TileHeight),
+	}
+}
+
+// ForEach calls fn for every cell holding a tile, row by row within each chunk
+func (g *TileGrid) ForEach(fn func(x, y int, tile *Tile)) {
+	if g == nil {
+		return
+	}
+	for _, chunk := range g.chunks {
+		if chunk != nil {
+			g.forEachInChunk(chunk, fn)
+		}
+	}
+}
+
+func (g *TileGrid) forEachInChunk(chunk *tileChunk, fn func(x, y int, tile *Tile)) {
+	for i, index := range chunk.cells {
+		if index != 0 {
+			fn(chunk.x*ChunkSize+i%ChunkSize, chunk.y*ChunkSize+i/ChunkSize, g.kinds[index])
+		}
+	}
+}
+
+// Draw renders the chunks that overlap the view, using their baked textures
+// where they have them
+func (g *TileGrid) Draw(view rl.Rectangle, tint rl.Color) {
+	g.forEachChunkIn(view, func(chunk *tileChunk) {
+		if chunk.isBaked {
+			texture := chunk.texture.Texture
+			// Render textures are stored upside down
+			source := rl.NewRectangle(0, 0, float32(texture.Width), -float32(texture.Height))
+			rl.DrawTextureRec(texture, source, g.chunkOrigin(chunk), tint)
+			return
+		}
+		g.forEachInChunk(chunk, func(x, y int, tile *Tile) {
+			tile.Draw(g.TilePosition(x, y, tile), tint)
+		})
+	})
+}
+
+// Bake draws the chunks that overlap the view into textures, so each is drawn
+// as one image from then on, and releases the ones that have been out of view
+// for a while. Call it before BeginMode2D, drawing into a texture resets the camera
+func (g *TileGrid) Bake(view rl.Rectangle) {
+	if g == nil {
+		return
+	}
+	g.frame++
+	g.forEachChunkIn(view, func(chunk *tileChunk) {
+		chunk.lastSeen = g.frame
+		if !chunk.isBaked && g.bakeable(chunk) {
+			g.bake(chunk)
+		}
+	})
+
+	kept := g.baked[:0]
+	for _, chunk := range g.baked {
+		if g.frame-chunk.lastSeen > bakeKeepFrames {
+			rl.UnloadRenderTexture(chunk.texture)
+			chunk.isBaked = false
+			continue
+		}
+		kept = append(kept, chunk)
+	}
+	g.baked = kept
+}
+
+// Unload releases the baked chunk textures
+func (g *TileGrid) Unload() {
+	if g == nil {
+		return
+	}
+	for _, chunk := range g.baked {
+		rl.UnloadRenderTexture(chunk.texture)
+		chunk.isBaked = false
+	}
+	g.baked = nil
+}
+
+// PixelHeight returns the height of the grid in pixels
+func (g *TileGrid) PixelHeight() float32 {
+	if g == nil {
+		return 0
+	}
+	return float32(g.Height) * float32(g.TileHeight)
+}
+
+// bakeable reports whether every tile of a chunk fits in its cell, tiles
+// reaching into the chunk above would be cut off by the texture
+func (g *TileGrid) bakeable(chunk *tileChunk) bool {
+	for _, index := range chunk.cells {
+		if index != 0 && g.kinds[index].height(g.TileHeight) > float32(g.TileHeight) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *TileGrid) bake(chunk *tileChunk) {
+	origin := g.chunkOrigin(chunk)
+	chunk.texture = rl.LoadRenderTexture(ChunkSize*g.TileWidth, ChunkSize*g.TileHeight)
+
+	rl.BeginTextureMode(chunk.texture)
+	rl.ClearBackground(rl.Blank)
+	g.forEachInChunk(chunk, func(x, y int, tile *Tile) {
+		position := g.TilePosition(x, y, tile)
+		tile.Draw(rl.Vector2{X: position.X - origin.X, Y: position.Y - origin.Y}, rl.White)
+	})
+	rl.EndTextureMode()
+
+	chunk.isBaked = true
+	g.baked = append(g.baked, chunk)
+}
+
+// unbake drops a chunk's texture after one of its cells changed
+func (g *TileGrid) unbake(chunk *tileChunk) {
+	if !chunk.isBaked {
+		return
+	}
+	rl.UnloadRenderTexture(chunk.texture)
+	chunk.isBaked = false
+	for i, baked := range g.baked {
+		if baked == chunk {
+			g.baked = append(g.baked[:i], g.baked[i+1:]...)
+			break
+		}
+	}
+}
+
+// forEachChunkIn calls fn for every chunk with tiles that overlaps the view,
+// allowing for tiles that reach above their cells
+func (g *TileGrid) forEachChunkIn(view rl.Rectangle, fn func(chunk *tileChunk)) {
+	if g == nil || g.TileWidth <= 0 || g.TileHeight <= 0 {
+		return
+	}
+
+	chunkWidth := float32(ChunkSize) * float32(g.TileWidth)
+	chunkHeight := float32(ChunkSize) * float32(g.TileHeight)
+	chunksHigh := len(g.chunks) / max(g.chunksWide, 1)
+
+	left := max(int(floorDiv(view.X, chunkWidth)), 0)
+	right := min(int(floorDiv(view.X+view.Width, chunkWidth)), g.chunksWide-1)
+	top := max(int(floorDiv(view.Y, chunkHeight)), 0)
+	bottom := min(int(floorDiv(view.Y+view.Height+g.overhang, chunkHeight)), chunksHigh-1)
+
+	for y := top; y <= bottom; y++ {
+		for x := left; x <= right; x++ {
+			if chunk := g.chunks[y*g.chunksWide+x]; chunk != nil {
+				fn(chunk)
+			}
+		}
+	}
+}
+
+// chunkOrigin returns the top left corner of a chunk in world coordinates
+func (g *TileGrid) chunkOrigin(chunk *tileChunk) rl.Vector2 {
+	return rl.Vector2{
+		X: float32(chunk.x*ChunkSize) * float32(g.TileWidth),
+		Y: float32(chunk.y*ChunkSize) * float32(g.TileHeight),
+	}
+}
+
+// floorDiv divides rounding down, so views left of or above the map land on chunk -1
+func floorDiv(a, b float32) float32 {
+	quotient := a / b
+	if quotient < 0 && float32(int(quotient)) != quotient {
+		return float32(int(quotient) - 1)
+	}
+	return float32(int(quotient))
+}
+
+// CameraView returns the part of the world a camera shows, ignoring rotation
+func CameraView(camera rl.Camera2D) rl.Rectangle {
+	zoom := camera.Zoom
+	if zoom <= 0 {
+		zoom = 1
+	}
+	return rl.NewRectangle(
+		camera.Target.X-camera.Offset.X/zoom,
+		camera.Target.Y-camera.Offset.Y/zoom,
+		float32(rl.GetScreenWidth())/zoom,
+		float32(rl.GetScreenHeight())/zoom,
+	)
+}
@@ -0,0 +1,147 @@
+package game_manager
+
+import (
+	"math/rand"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// The benchmark level is 300 screens of 960 by 512 pixels
+const (
+	benchScreens      = 300
+	benchScreenWidth  = 960
+	benchScreenHeight = 512
+)
+
+// perTile is how a tile used to be stored, one per cell with its own position
+type perTile struct {
+	Texture  rl.Texture2D
+	Source   rl.Rectangle
+	Position rl.Vector2
+}
+
+// benchCells lays out a level of tileset IDs: solid ground with gaps and
+// floating platforms, 0 for empty cells
+func benchCells() [][]int {
+	width := benchScreens * benchScreenWidth / TileSize
+	height := benchScreenHeight / TileSize
+	random := rand.New(rand.NewSource(1))
+	cells := make([][]int, height)
+	for y := range cells {
+		cells[y] = make([]int, width)
+	}
+
+	for x := 0; x < width; x++ {
+		if random.Intn(12) == 0 {
+			continue
+		}
+		for y := height - 3; y < height; y++ {
+			cells[y][x] = 11
+		}
+		if random.Intn(4) == 0 {
+			cells[height-7-random.Intn(4)][x] = 24
+		}
+	}
+	return cells
+}
+
+// benchKinds returns one shared tile per ID the benchmark level uses, cut from
+// a tileset 9 tiles wide. Building and culling never touch the texture
+func benchKinds() map[int]*Tile {
+	const columns = 9
+	kinds := map[int]*Tile{}
+	for _, id := range []int{11, 24} {
+		kinds[id] = &Tile{
+			Source:     rl.NewRectangle(float32((id-1)%columns*TileSize), float32((id-1)/columns*TileSize), TileSize, TileSize),
+			Definition: DefaultTileDefinition,
+		}
+	}
+	return kinds
+}
+
+func benchGrid(cells [][]int, kinds map[int]*Tile) *TileGrid {
+	grid := NewTileGrid(len(cells[0]), len(cells), TileSize, TileSize)
+	for y, row := range cells {
+		for x, id := range row {
+			if id != 0 {
+				grid.Set(x, y, kinds[id])
+			}
+		}
+	}
+	return grid
+}
+
+func BenchmarkTileGridBuild(b *testing.B) {
+	cells, kinds := benchCells(), benchKinds()
+
+	b.Run("per-tile", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tiles := make([][]*perTile, len(cells))
+			for y, row := range cells {
+				tiles[y] = make([]*perTile, len(row))
+				for x, id := range row {
+					if id != 0 {
+						tiles[y][x] = &perTile{
+							Texture:  kinds[id].Texture,
+							Source:   kinds[id].Source,
+							Position: rl.Vector2{X: float32(x * TileSize), Y: float32(y * TileSize)},
+						}
+					}
+				}
+			}
+		}
+	})
+	b.Run("chunked", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchGrid(cells, kinds)
+		}
+	})
+}
+
+// BenchmarkTileGridCulling visits the tiles a frame would draw with the view
+// scrolling along the level, every tile against only the chunks in view
+func BenchmarkTileGridCulling(b *testing.B) {
+	grid := benchGrid(benchCells(), benchKinds())
+	width := grid.Width * TileSize
+	visited := 0
+	visit := func(x, y int, tile *Tile) { visited++ }
+
+	b.Run("all", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			grid.ForEach(visit)
+		}
+	})
+	b.Run("culled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			view := rl.NewRectangle(float32(i*8%width), 0, benchScreenWidth, benchScreenHeight)
+			grid.forEachChunkIn(view, func(chunk *tileChunk) {
+				grid.forEachInChunk(chunk, visit)
+			})
+		}
+	})
+}
+
+func TestTileGridCullingSkipsChunksOutOfView(t *testing.T) {
+	grid := benchGrid(benchCells(), benchKinds())
+	view := rl.NewRectangle(float32(10*ChunkSize*TileSize), 0, benchScreenWidth, benchScreenHeight)
+
+	grid.forEachChunkIn(view, func(chunk *tileChunk) {
+		if origin := grid.chunkOrigin(chunk); !rl.CheckCollisionRecs(view, rl.NewRectangle(origin.X, origin.Y, ChunkSize*TileSize, ChunkSize*TileSize)) {
+			t.Errorf("chunk at %v is outside the view %v", origin, view)
+		}
+	})
+
+	var all, culled int
+	grid.ForEach(func(int, int, *Tile) { all++ })
+	grid.forEachChunkIn(view, func(chunk *tileChunk) {
+		grid.forEachInChunk(chunk, func(int, int, *Tile) { culled++ })
+	})
+	if culled == 0 || culled*100 > all {
+		t.Errorf("a screen of a %d screen level visits %d of %d tiles", benchScreens, culled, all)
+	}
+}
@@ -0,0 +1,622 @@
+package game_manager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grcatterall/go-game/classes/assets"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tiled keeps how a tile is flipped in the top bits of its global ID
+const (
+	flippedHorizontally = 0x80000000
+	flippedVertically   = 0x40000000
+	flippedDiagonally   = 0x20000000
+	gidMask             = 0x0fffffff
+)
+
+// tiledProperty is a custom property, Value is a string, number or bool
+type tiledProperty struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"`
+	Value any    `json:"value"`
+}
+
+// tiledMap is a Tiled map in the layout of its JSON format, TMX files are
+// converted into it so both formats are built the same way
+type tiledMap struct {
+	Width      int             `json:"width"`
+	Height     int             `json:"height"`
+	TileWidth  int             `json:"tilewidth"`
+	TileHeight int             `json:"tileheight"`
+	Infinite   bool            `json:"infinite"`
+	Properties []tiledProperty `json:"properties,omitempty"`
+	Tilesets   []tiledTileset  `json:"tilesets"`
+	Layers     []tiledLayer    `json:"layers"`
+}
+
+// tiledTileset is either one image cut into tiles or a collection of separate
+// tile images. Source names an external .tsx or .tsj file holding the rest
+type tiledTileset struct {
+	FirstGID   uint32         `json:"firstgid"`
+	Source     string         `json:"source,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	TileWidth  int            `json:"tilewidth,omitempty"`
+	TileHeight int            `json:"tileheight,omitempty"`
+	Spacing    int            `json:"spacing,omitempty"`
+	Margin     int            `json:"margin,omitempty"`
+	Columns    int            `json:"columns,omitempty"`
+	TileCount  int            `json:"tilecount,omitempty"`
+	Image      string         `json:"image,omitempty"`
+	Tiles      []tiledTile    `json:"tiles,omitempty"`
+	WangSets   []tiledWangSet `json:"wangsets,omitempty"`
+	directory  string         // where image paths are relative to
+}
+
+// tiledTile holds the image and properties of one tile of a tileset
+type tiledTile struct {
+	ID         uint32          `json:"id"`
+	Image      string          `json:"image,omitempty"`
+	Properties []tiledProperty `json:"properties,omitempty"`
+}
+
+// tiledLayer is a tile layer, object group or group of other layers
+type tiledLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Visible     bool            `json:"visible"`
+	Opacity     float32         `json:"opacity"`
+	Width       int             `json:"width,omitempty"`
+	Height      int             `json:"height,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	Encoding    string          `json:"encoding,omitempty"`
+	Compression string          `json:"compression,omitempty"`
+	Objects     []tiledObject   `json:"objects,omitempty"`
+	Layers      []tiledLayer    `json:"layers,omitempty"`
+	Properties  []tiledProperty `json:"properties,omitempty"`
+	gids        []uint32
+}
+
+// tiledObject is an object in an object group, older maps use Type for its class
+type tiledObject struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Class      string          `json:"class,omitempty"`
+	X          float32         `json:"x"`
+	Y          float32         `json:"y"`
+	Width      float32         `json:"width"`
+	Height     float32         `json:"height"`
+	Point      bool            `json:"point,omitempty"`
+	Polyline   []tiledPoint    `json:"polyline,omitempty"`
+	Polygon    []tiledPoint    `json:"polygon,omitempty"`
+	GID        uint32          `json:"gid,omitempty"`
+	Properties []tiledProperty `json:"properties,omitempty"`
+}
+
+// tiledPoint is a corner of a polyline or polygon, relative to its object
+type tiledPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// LoadTiledMap loads a map saved by the Tiled editor as TMX or JSON (.tmj).
+// Every tile layer is drawn, the one with a true "collision" property (or the
+// first one when none has it) becomes the layer characters collide with, and
+// object layers are kept for the game to place things from. Objects showing a
+// tile image, like the ones in assets/levels/objects.tsx, become props. Layers
+// after the collision layer draw in front of the characters. Tiles painted with
+// a terrain of the tilesets' Wang sets get their edges and corners picked to
+// match their neighbours
+func LoadTiledMap(path string) (*TileMap, error) {
+	data, err := readTiledMap(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tileMap, err := buildTileMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading map %s: %w", path, err)
+	}
+	return tileMap, nil
+}
+
+// readTiledMap reads a TMX or JSON map along with any external tilesets it
+// uses, with its terrain tiles picked
+func readTiledMap(path string) (*tiledMap, error) {
+	var data *tiledMap
+	var err error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		data, err = readTMX(path)
+	case ".tmj", ".json":
+		data, err = readTMJ(path)
+	default:
+		err = fmt.Errorf("unknown map format")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading map %s: %w", path, err)
+	}
+
+	if data.Infinite {
+		return nil, fmt.Errorf("loading map %s: infinite maps are not supported", path)
+	}
+
+	for i := range data.Tilesets {
+		if err := resolveTileset(&data.Tilesets[i], filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("loading map %s: %w", path, err)
+		}
+	}
+
+	autotileMap(data)
+	return data, nil
+}
+
+// buildTileMap places the tiles, props and objects of a parsed map
+func buildTileMap(data *tiledMap) (*TileMap, error) {
+	builder := newTiledBuilder(data)
+	if err := builder.addLayers(data.Layers, true, 1); err != nil {
+		builder.tileMap.Unload()
+		return nil, err
+	}
+
+	tileMap := builder.tileMap
+	collision := -1
+	for i, layer := range tileMap.Layers {
+		if layer.Tiles != nil && (collision < 0 || layer.Properties.Bool("collision")) {
+			collision = i
+			if layer.Properties.Bool("collision") {
+				break
+			}
+		}
+	}
+	if collision >= 0 {
+		tileMap.Tiles = tileMap.Layers[collision].Tiles
+	}
+
+	// Layers above the collision layer draw over the characters, unless a
+	// "foreground" property says otherwise
+	for i, layer := range tileMap.Layers {
+		layer.Foreground = collision >= 0 && i > collision
+		if _, ok := layer.Properties["foreground"]; ok {
+			layer.Foreground = layer.Properties.Bool("foreground")
+		}
+	}
+
+	return tileMap, nil
+}
+
+// readTMJ reads a map in Tiled's JSON format
+func readTMJ(path string) (*tiledMap, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data tiledMap
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	if err := decodeTMJLayers(data.Layers); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// decodeTMJLayers reads the tile data of every tile layer, which is either an
+// array of IDs or a base64 string
+func decodeTMJLayers(layers []tiledLayer) error {
+	for i := range layers {
+		layer := &layers[i]
+		if err := decodeTMJLayers(layer.Layers); err != nil {
+			return err
+		}
+		if layer.Type != "tilelayer" || len(layer.Data) == 0 {
+			continue
+		}
+
+		if layer.Data[0] == '[' {
+			if err := json.Unmarshal(layer.Data, &layer.gids); err != nil {
+				return fmt.Errorf("layer %q: %w", layer.Name, err)
+			}
+			continue
+		}
+
+		var text string
+		if err := json.Unmarshal(layer.Data, &text); err != nil {
+			return fmt.Errorf("layer %q: %w", layer.Name, err)
+		}
+		gids, err := decodeTileData(layer.Encoding, layer.Compression, text)
+		if err != nil {
+			return fmt.Errorf("layer %q: %w", layer.Name, err)
+		}
+		layer.gids = gids
+	}
+	return nil
+}
+
+// decodeTileData decodes CSV or base64 tile data, optionally zlib or gzip compressed
+func decodeTileData(encoding, compression, text string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+
+		var reader io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if reader, err = zlib.NewReader(reader); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if reader, err = gzip.NewReader(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported tile data compression %q", compression)
+		}
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+	return nil, fmt.Errorf("unsupported tile data encoding %q", encoding)
+}
+
+// resolveTileset loads an external tileset over its reference in the map
+func resolveTileset(tileset *tiledTileset, mapDirectory string) error {
+	tileset.directory = mapDirectory
+	if tileset.Source == "" {
+		return nil
+	}
+
+	path := filepath.Join(mapDirectory, tileset.Source)
+	var external *tiledTileset
+	var err error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsx":
+		external, err = readTSX(path)
+	case ".tsj", ".json":
+		external, err = readTSJ(path)
+	default:
+		err = fmt.Errorf("unknown tileset format")
+	}
+	if err != nil {
+		return fmt.Errorf("tileset %s: %w", tileset.Source, err)
+	}
+
+	// The source is kept so the map is saved still pointing at the external file
+	external.FirstGID = tileset.FirstGID
+	external.Source = tileset.Source
+	external.directory = filepath.Dir(path)
+	*tileset = *external
+	return nil
+}
+
+// readTSJ reads a tileset in Tiled's JSON format
+func readTSJ(path string) (*tiledTileset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tileset tiledTileset
+	if err := json.Unmarshal(raw, &tileset); err != nil {
+		return nil, err
+	}
+	return &tileset, nil
+}
+
+// convertProperties turns Tiled's property list into a lookup by name
+func convertProperties(properties []tiledProperty) Properties {
+	converted := Properties{}
+	for _, property := range properties {
+		converted[property.Name] = fmt.Sprint(property.Value)
+	}
+	return converted
+}
+
+// tiledBuilder turns a parsed map into a TileMap, loading tileset textures as tiles use them
+type tiledBuilder struct {
+	data     *tiledMap
+	tileMap  *TileMap
+	kinds    map[uint32]*Tile // one tile for every global ID in use, flipped ones included
+	textures map[string]*assets.TextureHandle
+}
+
+// newTiledBuilder creates a builder for a parsed map, starting from an empty TileMap
+func newTiledBuilder(data *tiledMap) *tiledBuilder {
+	return &tiledBuilder{
+		data: data,
+		tileMap: &TileMap{
+			Properties: convertProperties(data.Properties),
+			TileWidth:  int32(data.TileWidth),
+			TileHeight: int32(data.TileHeight),
+		},
+		kinds:    map[uint32]*Tile{},
+		textures: map[string]*assets.TextureHandle{},
+	}
+}
+
+// addLayers adds tile and object layers in draw order, flattening groups into
+// their children with the group's visibility and opacity applied
+func (b *tiledBuilder) addLayers(layers []tiledLayer, visible bool, opacity float32) error {
+	for _, layer := range layers {
+		layerVisible := visible && layer.Visible
+		layerOpacity := opacity * layer.Opacity
+
+		switch layer.Type {
+		case "tilelayer":
+			tileLayer, err := b.tileLayer(layer)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", layer.Name, err)
+			}
+			tileLayer.Visible = layerVisible
+			tileLayer.Opacity = layerOpacity
+			b.tileMap.Layers = append(b.tileMap.Layers, tileLayer)
+		case "objectgroup":
+			b.tileMap.ObjectLayers = append(b.tileMap.ObjectLayers, objectLayer(layer))
+
+			props, err := b.props(layer)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", layer.Name, err)
+			}
+			if len(props) > 0 {
+				b.tileMap.Layers = append(b.tileMap.Layers, &Layer{
+					Name:       layer.Name,
+					Props:      props,
+					Visible:    layerVisible,
+					Opacity:    layerOpacity,
+					Properties: convertProperties(layer.Properties),
+				})
+			}
+		case "group":
+			if err := b.addLayers(layer.Layers, layerVisible, layerOpacity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tileLayer places a layer's tiles, tiles taller than the grid sit on the bottom of their cell like in Tiled
+func (b *tiledBuilder) tileLayer(layer tiledLayer) (*Layer, error) {
+	if len(layer.gids) != layer.Width*layer.Height {
+		return nil, fmt.Errorf("has %d tiles, expected %d", len(layer.gids), layer.Width*layer.Height)
+	}
+
+	tiles := NewTileGrid(layer.Width, layer.Height, int32(b.data.TileWidth), int32(b.data.TileHeight))
+	for y := 0; y < layer.Height; y++ {
+		for x := 0; x < layer.Width; x++ {
+			gid := layer.gids[y*layer.Width+x]
+			if gid&gidMask == 0 {
+				continue
+			}
+
+			kind, err := b.flippedKind(gid)
+			if err != nil {
+				return nil, err
+			}
+			tiles.Set(x, y, kind)
+		}
+	}
+
+	return &Layer{
+		Name:       layer.Name,
+		Tiles:      tiles,
+		Properties: convertProperties(layer.Properties),
+	}, nil
+}
+
+// flippedKind returns the tile for a global ID with its flip bits, shared by every cell holding it
+func (b *tiledBuilder) flippedKind(gid uint32) (*Tile, error) {
+	if kind, ok := b.kinds[gid]; ok {
+		return kind, nil
+	}
+
+	kind, err := b.kind(gid & gidMask)
+	if err != nil || gid&^gidMask == 0 {
+		return kind, err
+	}
+
+	flipped := *kind
+	flipped.FlipX = gid&flippedHorizontally != 0
+	flipped.FlipY = gid&flippedVertically != 0
+	b.kinds[gid] = &flipped
+	return &flipped, nil
+}
+
+// props turns the objects of a group that show a tile image into props
+func (b *tiledBuilder) props(layer tiledLayer) ([]*Prop, error) {
+	var props []*Prop
+	for _, object := range layer.Objects {
+		if object.GID&gidMask == 0 {
+			continue
+		}
+
+		kind, err := b.kind(object.GID & gidMask)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", object.ID, err)
+		}
+
+		width, height := object.Width, object.Height
+		if width == 0 || height == 0 {
+			width, height = kind.Source.Width, kind.Source.Height
+		}
+
+		// Like in Tiled, the object's own properties override its tile's
+		properties := Properties{}
+		for name, value := range kind.Properties {
+			properties[name] = value
+		}
+		for name, value := range convertProperties(object.Properties) {
+			properties[name] = value
+		}
+
+		props = append(props, &Prop{
+			ObjectID:   object.ID,
+			Texture:    kind.Texture,
+			Source:     kind.Source,
+			Position:   rl.Vector2{X: object.X, Y: object.Y - height},
+			Width:      width,
+			Height:     height,
+			FlipX:      object.GID&flippedHorizontally != 0,
+			FlipY:      object.GID&flippedVertically != 0,
+			Properties: properties,
+		})
+	}
+	return props, nil
+}
+
+// objectLayer converts an object group, tile objects are moved so Y is their top like every other object
+func objectLayer(layer tiledLayer) *ObjectLayer {
+	objects := make([]*MapObject, len(layer.Objects))
+	for i, object := range layer.Objects {
+		class := object.Class
+		if class == "" {
+			class = object.Type
+		}
+
+		y := object.Y
+		if object.GID != 0 {
+			y -= object.Height
+		}
+
+		objects[i] = &MapObject{
+			ID:         object.ID,
+			Name:       object.Name,
+			Class:      class,
+			X:          object.X,
+			Y:          y,
+			Width:      object.Width,
+			Height:     object.Height,
+			Point:      object.Point,
+			Closed:     len(object.Polygon) > 0,
+			Properties: convertProperties(object.Properties),
+		}
+		for _, point := range append(object.Polyline, object.Polygon...) {
+			objects[i].Points = append(objects[i].Points, rl.Vector2{X: object.X + point.X, Y: object.Y + point.Y})
+		}
+	}
+
+	return &ObjectLayer{
+		Name:       layer.Name,
+		Objects:    objects,
+		Properties: convertProperties(layer.Properties),
+	}
+}
+
+// kind returns the texture, source rectangle and properties shared by every tile with a global ID
+func (b *tiledBuilder) kind(gid uint32) (*Tile, error) {
+	if kind, ok := b.kinds[gid]; ok {
+		return kind, nil
+	}
+
+	// The tile belongs to the tileset with the highest first ID not above it
+	var tileset *tiledTileset
+	for i := range b.data.Tilesets {
+		if b.data.Tilesets[i].FirstGID <= gid && (tileset == nil || b.data.Tilesets[i].FirstGID > tileset.FirstGID) {
+			tileset = &b.data.Tilesets[i]
+		}
+	}
+	if tileset == nil {
+		return nil, fmt.Errorf("tile %d is not in any tileset", gid)
+	}
+
+	id := gid - tileset.FirstGID
+	// Collections can skip IDs, only a tileset cut from one image has them all in a row
+	if tileset.Image != "" && tileset.TileCount > 0 && int(id) >= tileset.TileCount {
+		return nil, fmt.Errorf("tile %d is past the end of tileset %q", gid, tileset.Name)
+	}
+	kind := &Tile{Properties: Properties{}}
+
+	var image string
+	for _, tile := range tileset.Tiles {
+		if tile.ID == id {
+			kind.Properties = convertProperties(tile.Properties)
+			image = tile.Image
+		}
+	}
+
+	definition, err := ParseTileDefinition(kind.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("tileset %q tile %d: %w", tileset.Name, id, err)
+	}
+	kind.Definition = definition
+
+	if image != "" {
+		// A tile from a collection of images uses the whole image
+		texture := b.texture(filepath.Join(tileset.directory, image))
+		kind.Texture = texture
+		kind.Source = rl.Rectangle{Width: float32(texture.Width), Height: float32(texture.Height)}
+	} else if tileset.Image != "" {
+		texture := b.texture(tilesetImage(tileset))
+		columns := tileset.Columns
+		if columns <= 0 && tileset.TileWidth > 0 {
+			columns = (int(texture.Width) - 2*tileset.Margin + tileset.Spacing) / (tileset.TileWidth + tileset.Spacing)
+		}
+		if columns <= 0 {
+			return nil, fmt.Errorf("tileset %q has no columns", tileset.Name)
+		}
+
+		kind.Texture = texture
+		kind.Source = rl.Rectangle{
+			X:      float32(tileset.Margin + int(id)%columns*(tileset.TileWidth+tileset.Spacing)),
+			Y:      float32(tileset.Margin + int(id)/columns*(tileset.TileHeight+tileset.Spacing)),
+			Width:  float32(tileset.TileWidth),
+			Height: float32(tileset.TileHeight),
+		}
+	} else {
+		return nil, fmt.Errorf("tileset %q has no image for tile %d", tileset.Name, id)
+	}
+
+	b.kinds[gid] = kind
+	return kind, nil
+}
+
+// texture loads a tileset image once, the map releases it when unloaded
+func (b *tiledBuilder) texture(path string) rl.Texture2D {
+	handle, ok := b.textures[path]
+	if !ok {
+		handle = assets.Default.LoadTexture(path)
+		b.textures[path] = handle
+		b.tileMap.textures = append(b.tileMap.textures, handle)
+	}
+	return handle.Texture
+}
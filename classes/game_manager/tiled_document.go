@@ -0,0 +1,423 @@
+package game_manager
+
+import (
+	"fmt"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// maxUndo is how many edits a TiledDocument remembers
+const maxUndo = 100
+
+// TiledDocument is a Tiled map opened for editing. Edits change the map's own
+// data, Build turns it into a TileMap to draw and play, and Save writes it back
+// in the format it was read from. While Autotile is set, painting a terrain
+// tile picks the edges and corners of it and its neighbours
+type TiledDocument struct {
+	Path     string
+	Modified bool
+	Autotile bool
+	data     *tiledMap
+	undo     []*tiledMap
+	redo     []*tiledMap
+	palette  *tiledBuilder
+	terrains mapTerrains
+}
+
+// PaletteTile is a tile the document's tilesets offer for painting
+type PaletteTile struct {
+	GID     uint32
+	Texture rl.Texture2D
+	Source  rl.Rectangle
+}
+
+// OpenTiledMap reads a TMX or JSON map for editing
+func OpenTiledMap(path string) (*TiledDocument, error) {
+	data, err := readTiledMap(path)
+	if err != nil {
+		return nil, err
+	}
+	return &TiledDocument{Path: path, Autotile: true, data: data, terrains: newMapTerrains(data.Tilesets)}, nil
+}
+
+// Build places the document's tiles, props and objects as they are now
+func (d *TiledDocument) Build() (*TileMap, error) {
+	tileMap, err := buildTileMap(d.data)
+	if err != nil {
+		return nil, fmt.Errorf("building map %s: %w", d.Path, err)
+	}
+	return tileMap, nil
+}
+
+// Close releases the palette's textures
+func (d *TiledDocument) Close() {
+	if d.palette != nil {
+		d.palette.tileMap.Unload()
+		d.palette = nil
+	}
+}
+
+// Width returns the width of the map in tiles
+func (d *TiledDocument) Width() int {
+	return d.data.Width
+}
+
+// Height returns the height of the map in tiles
+func (d *TiledDocument) Height() int {
+	return d.data.Height
+}
+
+// TileSize returns the width and height of the map's grid cells in pixels
+func (d *TiledDocument) TileSize() (int, int) {
+	return d.data.TileWidth, d.data.TileHeight
+}
+
+// Palette returns every tile of the tilesets cut from a single image, the
+// terrain tiles, in ID order. Collections of separate images are left out
+func (d *TiledDocument) Palette() []PaletteTile {
+	if d.palette == nil {
+		d.palette = newTiledBuilder(d.data)
+	}
+
+	var palette []PaletteTile
+	for _, tileset := range d.data.Tilesets {
+		if tileset.Image == "" || tileset.Columns <= 0 {
+			continue
+		}
+
+		rows := 0
+		if texture := d.palette.texture(tilesetImage(&tileset)); tileset.TileHeight > 0 {
+			rows = (int(texture.Height) - 2*tileset.Margin + tileset.Spacing) / (tileset.TileHeight + tileset.Spacing)
+		}
+
+		for id := 0; id < rows*tileset.Columns; id++ {
+			kind, err := d.palette.kind(tileset.FirstGID + uint32(id))
+			if err != nil {
+				continue
+			}
+			palette = append(palette, PaletteTile{GID: tileset.FirstGID + uint32(id), Texture: kind.Texture, Source: kind.Source})
+		}
+	}
+	return palette
+}
+
+// TileLayers returns the names of the map's tile layers, groups flattened, in draw order
+func (d *TiledDocument) TileLayers() []string {
+	var names []string
+	for _, layer := range d.tileLayers() {
+		names = append(names, layer.Name)
+	}
+	return names
+}
+
+// CollisionLayer returns the index of the tile layer characters collide with,
+// -1 when the map has no tile layers
+func (d *TiledDocument) CollisionLayer() int {
+	collision := -1
+	for i, layer := range d.tileLayers() {
+		if convertProperties(layer.Properties).Bool("collision") {
+			return i
+		}
+		if collision < 0 {
+			collision = i
+		}
+	}
+	return collision
+}
+
+// Tile returns the global ID at a cell of a tile layer, 0 for an empty or out of range cell
+func (d *TiledDocument) Tile(layer, x, y int) uint32 {
+	tiles := d.layerTiles(layer)
+	if tiles == nil || !d.inside(x, y) {
+		return 0
+	}
+	return tiles[y*d.data.Width+x] & gidMask
+}
+
+// SetTile places a tile in a cell of a tile layer, 0 empties it. It reports
+// whether anything changed
+func (d *TiledDocument) SetTile(layer, x, y int, gid uint32) bool {
+	if !d.setTile(layer, x, y, gid) {
+		return false
+	}
+	d.autotile(layer, x, y, x, y)
+	return true
+}
+
+// FillRect places a tile in every cell between two corners, inclusive
+func (d *TiledDocument) FillRect(layer, x0, y0, x1, y1 int, gid uint32) bool {
+	changed := false
+	for y := min(y0, y1); y <= max(y0, y1); y++ {
+		for x := min(x0, x1); x <= max(x0, x1); x++ {
+			changed = d.setTile(layer, x, y, gid) || changed
+		}
+	}
+	if changed {
+		d.autotile(layer, x0, y0, x1, y1)
+	}
+	return changed
+}
+
+func (d *TiledDocument) setTile(layer, x, y int, gid uint32) bool {
+	tiles := d.layerTiles(layer)
+	if tiles == nil || !d.inside(x, y) || tiles[y*d.data.Width+x] == gid {
+		return false
+	}
+	tiles[y*d.data.Width+x] = gid
+	d.Modified = true
+	return true
+}
+
+// autotile picks the terrain tiles of the cells between two corners and the
+// ones around them, whose edges may have changed
+func (d *TiledDocument) autotile(layer, x0, y0, x1, y1 int) {
+	layers := d.tileLayers()
+	if !d.Autotile || layer < 0 || layer >= len(layers) {
+		return
+	}
+	d.terrains.autotile(layers[layer], min(x0, x1)-1, min(y0, y1)-1, max(x0, x1)+1, max(y0, y1)+1)
+}
+
+// Fill flood fills the cells joined to x, y that hold the same tile
+func (d *TiledDocument) Fill(layer, x, y int, gid uint32) bool {
+	tiles := d.layerTiles(layer)
+	if tiles == nil || !d.inside(x, y) {
+		return false
+	}
+
+	target := tiles[y*d.data.Width+x]
+	if target == gid {
+		return false
+	}
+
+	minX, minY, maxX, maxY := x, y, x, y
+	stack := [][2]int{{x, y}}
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		cx, cy := cell[0], cell[1]
+		if !d.inside(cx, cy) || tiles[cy*d.data.Width+cx] != target {
+			continue
+		}
+		tiles[cy*d.data.Width+cx] = gid
+		minX, minY, maxX, maxY = min(minX, cx), min(minY, cy), max(maxX, cx), max(maxY, cy)
+		stack = append(stack, [2]int{cx + 1, cy}, [2]int{cx - 1, cy}, [2]int{cx, cy + 1}, [2]int{cx, cy - 1})
+	}
+
+	d.autotile(layer, minX, minY, maxX, maxY)
+	d.Modified = true
+	return true
+}
+
+// Resize changes the size of the map in tiles, keeping the top left corner
+// where it is. Objects are left in place even when they end up outside the map
+func (d *TiledDocument) Resize(width, height int) bool {
+	if width <= 0 || height <= 0 || (width == d.data.Width && height == d.data.Height) {
+		return false
+	}
+
+	for _, layer := range d.tileLayers() {
+		resized := make([]uint32, width*height)
+		for y := 0; y < min(height, layer.Height); y++ {
+			for x := 0; x < min(width, layer.Width); x++ {
+				resized[y*width+x] = layer.gids[y*layer.Width+x]
+			}
+		}
+		layer.gids = resized
+		layer.Width = width
+		layer.Height = height
+	}
+
+	d.data.Width = width
+	d.data.Height = height
+
+	// Cells that were on the edge of the map may now have empty neighbours
+	for i := range d.tileLayers() {
+		d.autotile(i, 0, 0, width-1, height-1)
+	}
+
+	d.Modified = true
+	return true
+}
+
+// AddObject adds a shape to the object layer named "objects", or the first
+// object layer when there is none with that name, creating it in an empty map.
+// Its ID is filled in and returned
+func (d *TiledDocument) AddObject(object MapObject) int {
+	group := d.objectGroup()
+
+	id := d.nextObjectID()
+	group.Objects = append(group.Objects, tiledObject{
+		ID:         id,
+		Name:       object.Name,
+		Class:      object.Class,
+		X:          object.X,
+		Y:          object.Y,
+		Width:      object.Width,
+		Height:     object.Height,
+		Point:      object.Point,
+		Properties: tiledPropertiesFrom(object.Properties),
+	})
+
+	d.Modified = true
+	return id
+}
+
+// pointPickRadius is how close to a point object a click has to be to pick it
+const pointPickRadius = 8
+
+// RemoveObjectAt removes the last placed object under a position, props
+// showing tile images excepted. It reports whether one was removed
+func (d *TiledDocument) RemoveObjectAt(position rl.Vector2) bool {
+	groups := d.objectGroups()
+	for g := len(groups) - 1; g >= 0; g-- {
+		group := groups[g]
+		for i := len(group.Objects) - 1; i >= 0; i-- {
+			object := group.Objects[i]
+			if object.GID != 0 {
+				continue
+			}
+
+			area := rl.NewRectangle(object.X, object.Y, object.Width, object.Height)
+			if object.Point || object.Width == 0 || object.Height == 0 {
+				area = rl.NewRectangle(object.X-pointPickRadius, object.Y-pointPickRadius, pointPickRadius*2, pointPickRadius*2)
+			}
+			if rl.CheckCollisionPointRec(position, area) {
+				group.Objects = append(group.Objects[:i], group.Objects[i+1:]...)
+				d.Modified = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checkpoint remembers the map as it is now so the next edit can be undone,
+// call it before every edit the user would undo in one step
+func (d *TiledDocument) Checkpoint() {
+	d.undo = append(d.undo, cloneTiledMap(d.data))
+	if len(d.undo) > maxUndo {
+		d.undo = d.undo[1:]
+	}
+	d.redo = nil
+}
+
+// Undo goes back to the last checkpoint, reporting whether there was one
+func (d *TiledDocument) Undo() bool {
+	if len(d.undo) == 0 {
+		return false
+	}
+	d.redo = append(d.redo, d.data)
+	d.data = d.undo[len(d.undo)-1]
+	d.undo = d.undo[:len(d.undo)-1]
+	d.Modified = true
+	return true
+}
+
+// Redo reapplies the last undone edit, reporting whether there was one
+func (d *TiledDocument) Redo() bool {
+	if len(d.redo) == 0 {
+		return false
+	}
+	d.undo = append(d.undo, d.data)
+	d.data = d.redo[len(d.redo)-1]
+	d.redo = d.redo[:len(d.redo)-1]
+	d.Modified = true
+	return true
+}
+
+// tileLayers returns every tile layer, groups flattened, in draw order
+func (d *TiledDocument) tileLayers() []*tiledLayer {
+	return collectLayers(d.data.Layers, "tilelayer", nil)
+}
+
+// objectGroups returns every object layer, groups flattened, in draw order
+func (d *TiledDocument) objectGroups() []*tiledLayer {
+	return collectLayers(d.data.Layers, "objectgroup", nil)
+}
+
+func collectLayers(layers []tiledLayer, kind string, collected []*tiledLayer) []*tiledLayer {
+	for i := range layers {
+		if layers[i].Type == kind {
+			collected = append(collected, &layers[i])
+		}
+		collected = collectLayers(layers[i].Layers, kind, collected)
+	}
+	return collected
+}
+
+// objectGroup returns the layer new objects go in
+func (d *TiledDocument) objectGroup() *tiledLayer {
+	groups := d.objectGroups()
+	for _, group := range groups {
+		if group.Name == "objects" {
+			return group
+		}
+	}
+	if len(groups) > 0 {
+		return groups[0]
+	}
+
+	d.data.Layers = append(d.data.Layers, tiledLayer{Type: "objectgroup", Name: "objects", Visible: true, Opacity: 1})
+	return &d.data.Layers[len(d.data.Layers)-1]
+}
+
+func (d *TiledDocument) nextObjectID() int {
+	id := 1
+	for _, group := range d.objectGroups() {
+		for _, object := range group.Objects {
+			id = max(id, object.ID+1)
+		}
+	}
+	return id
+}
+
+// layerTiles returns the global IDs of a tile layer, nil when there is no such layer
+func (d *TiledDocument) layerTiles(layer int) []uint32 {
+	layers := d.tileLayers()
+	if layer < 0 || layer >= len(layers) || len(layers[layer].gids) != d.data.Width*d.data.Height {
+		return nil
+	}
+	return layers[layer].gids
+}
+
+func (d *TiledDocument) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < d.data.Width && y < d.data.Height
+}
+
+// cloneTiledMap copies everything an edit can change, tilesets are shared
+func cloneTiledMap(data *tiledMap) *tiledMap {
+	clone := *data
+	clone.Layers = cloneTiledLayers(data.Layers)
+	return &clone
+}
+
+func cloneTiledLayers(layers []tiledLayer) []tiledLayer {
+	if layers == nil {
+		return nil
+	}
+	clone := make([]tiledLayer, len(layers))
+	for i, layer := range layers {
+		clone[i] = layer
+		clone[i].gids = append([]uint32(nil), layer.gids...)
+		clone[i].Objects = append([]tiledObject(nil), layer.Objects...)
+		clone[i].Layers = cloneTiledLayers(layer.Layers)
+	}
+	return clone
+}
+
+// tiledPropertiesFrom turns properties back into Tiled's list, sorted by name
+func tiledPropertiesFrom(properties Properties) []tiledProperty {
+	var converted []tiledProperty
+	for _, name := range properties.Names() {
+		converted = append(converted, tiledProperty{Name: name, Value: properties[name]})
+	}
+	return converted
+}
+
+// tilesetImage returns the path of a tileset's image
+func tilesetImage(tileset *tiledTileset) string {
+	return filepath.Join(tileset.directory, tileset.Image)
+}
@@ -0,0 +1,285 @@
+package game_manager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Save writes the document back to its file, as TMX or JSON depending on its extension
+func (d *TiledDocument) Save() error {
+	var raw []byte
+	var err error
+
+	switch strings.ToLower(filepath.Ext(d.Path)) {
+	case ".tmx":
+		raw, err = xml.MarshalIndent(tmxFromData(d.data), "", " ")
+		raw = append([]byte(xml.Header), raw...)
+	case ".tmj", ".json":
+		raw, err = json.MarshalIndent(tmjFromData(d.data), "", "  ")
+	default:
+		err = fmt.Errorf("unknown map format")
+	}
+	if err != nil {
+		return fmt.Errorf("saving map %s: %w", d.Path, err)
+	}
+
+	if err := os.WriteFile(d.Path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("saving map %s: %w", d.Path, err)
+	}
+	d.Modified = false
+	return nil
+}
+
+// tmxFromData converts a map into Tiled's XML layout, tile data is written as CSV
+func tmxFromData(data *tiledMap) tmxMap {
+	tmx := tmxMap{
+		Version:     "1.10",
+		Orientation: "orthogonal",
+		RenderOrder: "right-down",
+		Width:       data.Width,
+		Height:      data.Height,
+		TileWidth:   data.TileWidth,
+		TileHeight:  data.TileHeight,
+		Properties:  tmxPropertiesFrom(data.Properties),
+	}
+
+	for _, tileset := range data.Tilesets {
+		tmx.Tilesets = append(tmx.Tilesets, tmxTilesetFrom(tileset))
+	}
+
+	ids := &tmxIDs{layer: 1, object: 1}
+	tmx.Layers = tmxLayersFrom(data.Layers, ids)
+	tmx.NextLayerID = ids.layer
+	tmx.NextObjectID = ids.object
+
+	return tmx
+}
+
+// tmxIDs hands out layer IDs and tracks the next free object ID
+type tmxIDs struct {
+	layer  int
+	object int
+}
+
+func tmxLayersFrom(layers []tiledLayer, ids *tmxIDs) []tmxLayer {
+	var converted []tmxLayer
+	for _, layer := range layers {
+		tmx := tmxLayer{
+			ID:         ids.layer,
+			Name:       layer.Name,
+			Properties: tmxPropertiesFrom(layer.Properties),
+		}
+		ids.layer++
+
+		if !layer.Visible {
+			hidden := 0
+			tmx.Visible = &hidden
+		}
+		if layer.Opacity != 1 {
+			opacity := layer.Opacity
+			tmx.Opacity = &opacity
+		}
+
+		switch layer.Type {
+		case "tilelayer":
+			tmx.XMLName.Local = "layer"
+			tmx.Width = layer.Width
+			tmx.Height = layer.Height
+			tmx.Data = &tmxData{Encoding: "csv", CSV: csvTileData(layer.gids, layer.Width)}
+		case "objectgroup":
+			tmx.XMLName.Local = "objectgroup"
+			for _, object := range layer.Objects {
+				tmx.Objects = append(tmx.Objects, tmxObjectFrom(object))
+				ids.object = max(ids.object, object.ID+1)
+			}
+		case "group":
+			tmx.XMLName.Local = "group"
+			tmx.Layers = tmxLayersFrom(layer.Layers, ids)
+		default:
+			continue
+		}
+
+		converted = append(converted, tmx)
+	}
+	return converted
+}
+
+// csvTileData writes a layer's tiles one row per line, the way Tiled does
+func csvTileData(gids []uint32, width int) string {
+	var text strings.Builder
+	text.WriteString("\n")
+	for i, gid := range gids {
+		text.WriteString(strconv.FormatUint(uint64(gid), 10))
+		if i < len(gids)-1 {
+			text.WriteString(",")
+		}
+		if width > 0 && (i+1)%width == 0 {
+			text.WriteString("\n")
+		}
+	}
+	return text.String()
+}
+
+func tmxObjectFrom(object tiledObject) tmxObject {
+	tmx := tmxObject{
+		ID:         object.ID,
+		Name:       object.Name,
+		Class:      object.Class,
+		X:          object.X,
+		Y:          object.Y,
+		Width:      object.Width,
+		Height:     object.Height,
+		GID:        object.GID,
+		Properties: tmxPropertiesFrom(object.Properties),
+	}
+	if tmx.Class == "" {
+		tmx.Class = object.Type
+	}
+	if object.Point {
+		tmx.Point = &struct{}{}
+	}
+	if len(object.Polyline) > 0 {
+		tmx.Polyline = &tmxPoints{Points: formatPoints(object.Polyline)}
+	}
+	if len(object.Polygon) > 0 {
+		tmx.Polygon = &tmxPoints{Points: formatPoints(object.Polygon)}
+	}
+	return tmx
+}
+
+// formatPoints writes the corners of a polyline or polygon the way TMX files do
+func formatPoints(points []tiledPoint) string {
+	pairs := make([]string, len(points))
+	for i, point := range points {
+		pairs[i] = strconv.FormatFloat(float64(point.X), 'g', -1, 32) + "," + strconv.FormatFloat(float64(point.Y), 'g', -1, 32)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// tmxTilesetFrom writes a reference to an external tileset, or the whole
+// tileset when it lives in the map
+func tmxTilesetFrom(tileset tiledTileset) tmxTileset {
+	if tileset.Source != "" {
+		return tmxTileset{FirstGID: tileset.FirstGID, Source: tileset.Source}
+	}
+
+	tmx := tmxTileset{
+		FirstGID:   tileset.FirstGID,
+		Name:       tileset.Name,
+		TileWidth:  tileset.TileWidth,
+		TileHeight: tileset.TileHeight,
+		Spacing:    tileset.Spacing,
+		Margin:     tileset.Margin,
+		Columns:    tileset.Columns,
+		TileCount:  tileset.TileCount,
+	}
+	if tileset.Image != "" {
+		tmx.Image = &tmxImage{Source: tileset.Image}
+	}
+	for _, tile := range tileset.Tiles {
+		converted := tmxTile{ID: tile.ID, Properties: tmxPropertiesFrom(tile.Properties)}
+		if tile.Image != "" {
+			converted.Image = &tmxImage{Source: tile.Image}
+		}
+		tmx.Tiles = append(tmx.Tiles, converted)
+	}
+	for _, set := range tileset.WangSets {
+		converted := tmxWangSet{Name: set.Name, Type: set.Type, Tile: set.Tile}
+		for _, colour := range set.Colors {
+			converted.Colors = append(converted.Colors, tmxWangColor(colour))
+		}
+		for _, tile := range set.WangTiles {
+			converted.Tiles = append(converted.Tiles, tmxWangTile{TileID: tile.TileID, WangID: formatWangID(tile.WangID)})
+		}
+		tmx.WangSets = append(tmx.WangSets, converted)
+	}
+	return tmx
+}
+
+func tmxPropertiesFrom(properties []tiledProperty) *tmxPropertyList {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	converted := &tmxPropertyList{}
+	for _, property := range properties {
+		converted.Properties = append(converted.Properties, tmxProperty{
+			Name:  property.Name,
+			Type:  property.Type,
+			Value: fmt.Sprint(property.Value),
+		})
+	}
+	return converted
+}
+
+// tmjFromData copies a map ready to marshal as JSON, with tile data written as
+// arrays and external tilesets written as references
+func tmjFromData(data *tiledMap) any {
+	tmj := cloneTiledMap(data)
+	tmj.Properties = tmjProperties(data.Properties)
+	tmjLayers(tmj.Layers)
+
+	tmj.Tilesets = nil
+	for _, tileset := range data.Tilesets {
+		if tileset.Source != "" {
+			tileset = tiledTileset{FirstGID: tileset.FirstGID, Source: tileset.Source}
+		}
+		tmj.Tilesets = append(tmj.Tilesets, tileset)
+	}
+
+	// Tiled needs to know what kind of file it is reading
+	return struct {
+		Type        string `json:"type"`
+		Orientation string `json:"orientation"`
+		RenderOrder string `json:"renderorder"`
+		*tiledMap
+	}{"map", "orthogonal", "right-down", tmj}
+}
+
+func tmjLayers(layers []tiledLayer) {
+	for i := range layers {
+		layer := &layers[i]
+		layer.Properties = tmjProperties(layer.Properties)
+		if layer.Type == "tilelayer" {
+			layer.Data, _ = json.Marshal(layer.gids)
+			layer.Encoding = ""
+			layer.Compression = ""
+		}
+		for j := range layer.Objects {
+			if layer.Objects[j].Type == "" {
+				layer.Objects[j].Type = layer.Objects[j].Class
+			}
+			layer.Objects[j].Class = ""
+			layer.Objects[j].Properties = tmjProperties(layer.Objects[j].Properties)
+		}
+		tmjLayers(layer.Layers)
+	}
+}
+
+// tmjProperties types property values the way Tiled's JSON expects, properties
+// read from TMX files hold every value as text
+func tmjProperties(properties []tiledProperty) []tiledProperty {
+	var converted []tiledProperty
+	for _, property := range properties {
+		text := fmt.Sprint(property.Value)
+		switch property.Type {
+		case "int", "float", "object":
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				property.Value = number
+			}
+		case "bool":
+			if value, err := strconv.ParseBool(text); err == nil {
+				property.Value = value
+			}
+		default:
+			property.Value = text
+		}
+		converted = append(converted, property)
+	}
+	return converted
+}
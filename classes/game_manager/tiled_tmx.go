@@ -0,0 +1,343 @@
+package game_manager
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tmxProperty is a property element, long text values are written as its content
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:"value,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+// tmxPropertyList is a properties element, pointed to so it is left out when there are none
+type tmxPropertyList struct {
+	Properties []tmxProperty `xml:"property"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxTile struct {
+	ID         uint32           `xml:"id,attr"`
+	Properties *tmxPropertyList `xml:"properties"`
+	Image      *tmxImage        `xml:"image"`
+}
+
+type tmxTileset struct {
+	XMLName    xml.Name     `xml:"tileset"`
+	FirstGID   uint32       `xml:"firstgid,attr,omitempty"`
+	Source     string       `xml:"source,attr,omitempty"`
+	Name       string       `xml:"name,attr,omitempty"`
+	TileWidth  int          `xml:"tilewidth,attr,omitempty"`
+	TileHeight int          `xml:"tileheight,attr,omitempty"`
+	Spacing    int          `xml:"spacing,attr,omitempty"`
+	Margin     int          `xml:"margin,attr,omitempty"`
+	Columns    int          `xml:"columns,attr,omitempty"`
+	TileCount  int          `xml:"tilecount,attr,omitempty"`
+	Image      *tmxImage    `xml:"image"`
+	Tiles      []tmxTile    `xml:"tile"`
+	WangSets   []tmxWangSet `xml:"wangsets>wangset"`
+}
+
+type tmxWangSet struct {
+	Name   string         `xml:"name,attr"`
+	Type   string         `xml:"type,attr"`
+	Tile   int            `xml:"tile,attr"`
+	Colors []tmxWangColor `xml:"wangcolor"`
+	Tiles  []tmxWangTile  `xml:"wangtile"`
+}
+
+type tmxWangColor struct {
+	Name        string  `xml:"name,attr"`
+	Color       string  `xml:"color,attr"`
+	Tile        int     `xml:"tile,attr"`
+	Probability float64 `xml:"probability,attr"`
+}
+
+type tmxWangTile struct {
+	TileID uint32 `xml:"tileid,attr"`
+	WangID string `xml:"wangid,attr"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr,omitempty"`
+	Compression string `xml:"compression,attr,omitempty"`
+	Text        string `xml:",chardata"`
+	CSV         string `xml:",innerxml"` // only written, chardata would escape its line breaks
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr,omitempty"`
+	} `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int              `xml:"id,attr"`
+	Name       string           `xml:"name,attr,omitempty"`
+	Type       string           `xml:"type,attr,omitempty"`
+	Class      string           `xml:"class,attr,omitempty"`
+	X          float32          `xml:"x,attr,omitempty"`
+	Y          float32          `xml:"y,attr,omitempty"`
+	Width      float32          `xml:"width,attr,omitempty"`
+	Height     float32          `xml:"height,attr,omitempty"`
+	GID        uint32           `xml:"gid,attr,omitempty"`
+	Point      *struct{}        `xml:"point"`
+	Polyline   *tmxPoints       `xml:"polyline"`
+	Polygon    *tmxPoints       `xml:"polygon"`
+	Properties *tmxPropertyList `xml:"properties"`
+}
+
+// tmxPoints holds the corners of a polyline or polygon as "x,y x,y ..."
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// tmxLayer is any of layer, objectgroup, imagelayer or group. They share one
+// struct so the layers keep the order they are drawn in
+type tmxLayer struct {
+	XMLName    xml.Name
+	ID         int              `xml:"id,attr,omitempty"`
+	Name       string           `xml:"name,attr"`
+	Visible    *int             `xml:"visible,attr,omitempty"`
+	Opacity    *float32         `xml:"opacity,attr,omitempty"`
+	Width      int              `xml:"width,attr,omitempty"`
+	Height     int              `xml:"height,attr,omitempty"`
+	Properties *tmxPropertyList `xml:"properties"`
+	Data       *tmxData         `xml:"data"`
+	Objects    []tmxObject      `xml:"object"`
+	Layers     []tmxLayer       `xml:",any"`
+}
+
+type tmxMap struct {
+	XMLName      xml.Name         `xml:"map"`
+	Version      string           `xml:"version,attr,omitempty"`
+	Orientation  string           `xml:"orientation,attr,omitempty"`
+	RenderOrder  string           `xml:"renderorder,attr,omitempty"`
+	Width        int              `xml:"width,attr,omitempty"`
+	Height       int              `xml:"height,attr,omitempty"`
+	TileWidth    int              `xml:"tilewidth,attr,omitempty"`
+	TileHeight   int              `xml:"tileheight,attr,omitempty"`
+	Infinite     int              `xml:"infinite,attr"`
+	NextLayerID  int              `xml:"nextlayerid,attr,omitempty"`
+	NextObjectID int              `xml:"nextobjectid,attr,omitempty"`
+	Properties   *tmxPropertyList `xml:"properties"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:",any"`
+}
+
+// readTMX reads a map in Tiled's XML format
+func readTMX(path string) (*tiledMap, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmx tmxMap
+	if err := xml.Unmarshal(raw, &tmx); err != nil {
+		return nil, err
+	}
+
+	data := &tiledMap{
+		Width:      tmx.Width,
+		Height:     tmx.Height,
+		TileWidth:  tmx.TileWidth,
+		TileHeight: tmx.TileHeight,
+		Infinite:   tmx.Infinite != 0,
+		Properties: tmxProperties(tmx.Properties),
+	}
+	for _, tileset := range tmx.Tilesets {
+		converted, err := tmxTilesetData(tileset)
+		if err != nil {
+			return nil, err
+		}
+		data.Tilesets = append(data.Tilesets, converted)
+	}
+	if data.Layers, err = tmxLayers(tmx.Layers); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readTSX reads a tileset in Tiled's XML format
+func readTSX(path string) (*tiledTileset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tileset tmxTileset
+	if err := xml.Unmarshal(raw, &tileset); err != nil {
+		return nil, err
+	}
+
+	data, err := tmxTilesetData(tileset)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func tmxTilesetData(tileset tmxTileset) (tiledTileset, error) {
+	data := tiledTileset{
+		FirstGID:   tileset.FirstGID,
+		Source:     tileset.Source,
+		Name:       tileset.Name,
+		TileWidth:  tileset.TileWidth,
+		TileHeight: tileset.TileHeight,
+		Spacing:    tileset.Spacing,
+		Margin:     tileset.Margin,
+		Columns:    tileset.Columns,
+		TileCount:  tileset.TileCount,
+	}
+	if tileset.Image != nil {
+		data.Image = tileset.Image.Source
+	}
+	for _, tile := range tileset.Tiles {
+		converted := tiledTile{
+			ID:         tile.ID,
+			Properties: tmxProperties(tile.Properties),
+		}
+		if tile.Image != nil {
+			converted.Image = tile.Image.Source
+		}
+		data.Tiles = append(data.Tiles, converted)
+	}
+	for _, set := range tileset.WangSets {
+		converted := tiledWangSet{Name: set.Name, Type: set.Type, Tile: set.Tile}
+		for _, colour := range set.Colors {
+			converted.Colors = append(converted.Colors, tiledWangColor(colour))
+		}
+		for _, tile := range set.Tiles {
+			wangID, err := parseWangID(tile.WangID)
+			if err != nil {
+				return data, fmt.Errorf("wang set %q tile %d: %w", set.Name, tile.TileID, err)
+			}
+			converted.WangTiles = append(converted.WangTiles, tiledWangTile{TileID: tile.TileID, WangID: wangID})
+		}
+		data.WangSets = append(data.WangSets, converted)
+	}
+	return data, nil
+}
+
+// tmxLayers converts layer elements, skipping anything that isn't a layer such as editor settings
+func tmxLayers(layers []tmxLayer) ([]tiledLayer, error) {
+	var converted []tiledLayer
+
+	for _, layer := range layers {
+		data := tiledLayer{
+			Name:       layer.Name,
+			Visible:    layer.Visible == nil || *layer.Visible != 0,
+			Opacity:    1,
+			Width:      layer.Width,
+			Height:     layer.Height,
+			Properties: tmxProperties(layer.Properties),
+		}
+		if layer.Opacity != nil {
+			data.Opacity = *layer.Opacity
+		}
+
+		switch layer.XMLName.Local {
+		case "layer":
+			data.Type = "tilelayer"
+			if layer.Data != nil {
+				gids, err := tmxTileData(layer.Data)
+				if err != nil {
+					return nil, err
+				}
+				data.gids = gids
+			}
+		case "objectgroup":
+			data.Type = "objectgroup"
+			for _, object := range layer.Objects {
+				polyline, err := tmxPointList(object.Polyline)
+				if err != nil {
+					return nil, fmt.Errorf("object %d: %w", object.ID, err)
+				}
+				polygon, err := tmxPointList(object.Polygon)
+				if err != nil {
+					return nil, fmt.Errorf("object %d: %w", object.ID, err)
+				}
+				data.Objects = append(data.Objects, tiledObject{
+					ID:         object.ID,
+					Name:       object.Name,
+					Type:       object.Type,
+					Class:      object.Class,
+					X:          object.X,
+					Y:          object.Y,
+					Width:      object.Width,
+					Height:     object.Height,
+					Point:      object.Point != nil,
+					Polyline:   polyline,
+					Polygon:    polygon,
+					GID:        object.GID,
+					Properties: tmxProperties(object.Properties),
+				})
+			}
+		case "group":
+			data.Type = "group"
+			children, err := tmxLayers(layer.Layers)
+			if err != nil {
+				return nil, err
+			}
+			data.Layers = children
+		case "imagelayer":
+			data.Type = "imagelayer"
+		default:
+			continue
+		}
+
+		converted = append(converted, data)
+	}
+
+	return converted, nil
+}
+
+// tmxTileData reads a layer's tiles, written as tile elements when there is no encoding
+func tmxTileData(data *tmxData) ([]uint32, error) {
+	if data.Encoding == "" {
+		gids := make([]uint32, len(data.Tiles))
+		for i, tile := range data.Tiles {
+			gids[i] = tile.GID
+		}
+		return gids, nil
+	}
+	return decodeTileData(data.Encoding, data.Compression, data.Text)
+}
+
+// tmxPointList reads the corners of a polyline or polygon
+func tmxPointList(points *tmxPoints) ([]tiledPoint, error) {
+	if points == nil {
+		return nil, nil
+	}
+	var list []tiledPoint
+	for _, pair := range strings.Fields(points.Points) {
+		var point tiledPoint
+		if _, err := fmt.Sscanf(pair, "%g,%g", &point.X, &point.Y); err != nil {
+			return nil, fmt.Errorf("point %q: %w", pair, err)
+		}
+		list = append(list, point)
+	}
+	return list, nil
+}
+
+func tmxProperties(list *tmxPropertyList) []tiledProperty {
+	if list == nil {
+		return nil
+	}
+
+	converted := make([]tiledProperty, len(list.Properties))
+	for i, property := range list.Properties {
+		value := property.Value
+		if value == "" {
+			value = strings.TrimSpace(property.Text)
+		}
+		converted[i] = tiledProperty{Name: property.Name, Type: property.Type, Value: value}
+	}
+	return converted
+}
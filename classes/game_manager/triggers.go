@@ -0,0 +1,178 @@
+package game_manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TriggerEvent is what the player does with a trigger's zone to fire it
+type TriggerEvent string
+
+const (
+	TriggerEnter TriggerEvent = "enter" // fires when the player walks in
+	TriggerExit  TriggerEvent = "exit"  // fires when the player walks out
+	TriggerStay  TriggerEvent = "stay"  // fires once the player has been inside Delay seconds
+)
+
+// The verbs a trigger action starts with
+const (
+	ActionSpawn    = "spawn"    // spawn <wave>: place the enemies of a wave
+	ActionMessage  = "message"  // message [seconds] <text>: show text on screen
+	ActionOpen     = "open"     // open <door>: clear the solid tiles under a named object
+	ActionClose    = "close"    // close <door>: put a door's tiles back
+	ActionCutscene = "cutscene" // cutscene <name>: play a cutscene
+	ActionEnd      = "end"      // end: complete the level
+)
+
+// Classes of the map objects trigger actions name. A door's solid tiles are
+// cleared when it opens, one with an "open" property starting open, and a
+// cutscene's "lines" property holds what it shows, a line at a time
+const (
+	DoorClass     = "door"
+	CutsceneClass = "cutscene"
+)
+
+// Trigger defaults, in seconds
+const (
+	defaultMessageTime = 3
+	defaultStayDelay   = 1
+)
+
+// TriggerAction is one step of what a trigger does. Target names the wave,
+// door or cutscene, Text and Seconds are a message's
+type TriggerAction struct {
+	Verb    string
+	Target  string
+	Text    string
+	Seconds float32
+}
+
+// ParseTriggerActions reads a trigger's actions, one a line or separated by
+// semicolons, like
+//
+//	message 2 Ambush!; spawn raiders; close gate
+func ParseTriggerActions(text string) ([]TriggerAction, error) {
+	var actions []TriggerAction
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		action := TriggerAction{Verb: strings.ToLower(fields[0])}
+		args := fields[1:]
+		switch action.Verb {
+		case ActionSpawn, ActionOpen, ActionClose, ActionCutscene:
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%q needs one name", line)
+			}
+			action.Target = args[0]
+		case ActionMessage:
+			action.Seconds = defaultMessageTime
+			if len(args) > 1 {
+				if seconds, err := strconv.ParseFloat(args[0], 32); err == nil {
+					action.Seconds = float32(seconds)
+					args = args[1:]
+				}
+			}
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%q has no text", line)
+			}
+			action.Text = strings.Join(args, " ")
+		case ActionEnd:
+			if len(args) != 0 {
+				return nil, fmt.Errorf("%q takes nothing after it", line)
+			}
+		default:
+			return nil, fmt.Errorf("unknown trigger action %q", fields[0])
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// Trigger is a zone that runs its actions when the player enters it, leaves it
+// or stays in it, once unless Repeat is set
+type Trigger struct {
+	Name    string
+	Area    rl.Rectangle
+	On      TriggerEvent
+	Delay   float32 // how long the player stays before a stay trigger fires
+	Repeat  bool
+	Actions []TriggerAction
+	inside  bool
+	fired   bool
+	stayed  float32
+}
+
+// NewTrigger reads a trigger from its spawn. The "on" property picks the
+// event, enter when it's empty, "delay" and "repeat" set the rest and
+// "actions" lists what it does
+func NewTrigger(spawn Spawn) (*Trigger, error) {
+	actions, err := ParseTriggerActions(spawn.Properties.String("actions", ""))
+	if err != nil {
+		return nil, fmt.Errorf("trigger %q: %w", spawn.Name, err)
+	}
+
+	on := TriggerEvent(strings.ToLower(spawn.Properties.String("on", "")))
+	switch on {
+	case "":
+		on = TriggerEnter
+	case TriggerEnter, TriggerExit, TriggerStay:
+	default:
+		return nil, fmt.Errorf("trigger %q: unknown event %q", spawn.Name, on)
+	}
+
+	delay := spawn.Properties.Float("delay", 0)
+	if delay <= 0 {
+		delay = defaultStayDelay
+	}
+
+	return &Trigger{
+		Name:    spawn.Name,
+		Area:    spawn.Area,
+		On:      on,
+		Delay:   delay,
+		Repeat:  spawn.Properties.Bool("repeat"),
+		Actions: actions,
+	}, nil
+}
+
+// Update tells the trigger whether the player is inside it dt seconds on, and
+// reports whether it fires
+func (t *Trigger) Update(inside bool, dt float32) bool {
+	wasInside := t.inside
+	t.inside = inside
+	if t.fired && !t.Repeat {
+		return false
+	}
+
+	fire := false
+	switch t.On {
+	case TriggerEnter:
+		fire = inside && !wasInside
+	case TriggerExit:
+		fire = !inside && wasInside
+	case TriggerStay:
+		if !inside {
+			t.stayed = 0
+			return false
+		}
+		t.stayed += dt
+		if t.stayed >= t.Delay {
+			t.stayed = 0
+			fire = true
+		}
+	}
+
+	t.fired = t.fired || fire
+	return fire
+}
+
+// Reset forgets the trigger has fired and where the player was
+func (t *Trigger) Reset() {
+	t.inside, t.fired, t.stayed = false, false, 0
+}
@@ -1,29 +1,259 @@
 package helpers
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"fmt"
+
+	"github.com/grcatterall/go-game/classes/assets"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PlayMode controls what an animation does when it runs off the end of its frames
+type PlayMode int
+
+const (
+	Loop     PlayMode = iota // wrap back to the first frame
+	Once                     // hold the last frame
+	PingPong                 // bounce back and forth between the first and last frames
+	Reverse                  // loop from the last frame back to the first
+)
+
+// ParsePlayMode reads a play mode from the name used in sprite manifests
+func ParsePlayMode(name string) (PlayMode, error) {
+	switch name {
+	case "loop", "":
+		return Loop, nil
+	case "once":
+		return Once, nil
+	case "pingpong":
+		return PingPong, nil
+	case "reverse":
+		return Reverse, nil
+	}
+	return Loop, fmt.Errorf("unknown play mode %q", name)
+}
 
 type Animation struct {
 	Texture      rl.Texture2D
 	Frames       int32
-	FrameRec     rl.Rectangle
+	FrameRects   []rl.Rectangle // where each frame sits in the texture
+	FrameRec     rl.Rectangle   // the current frame's rectangle
+	Durations    []float32      // optional seconds per frame, overriding FrameSpeed
+	Offsets      []rl.Vector2   // optional per-frame offsets of frames trimmed in a packed sheet
 	CurrentFrame int32
-	FrameSpeed   float32
-	FrameCounter float32
+	FrameSpeed   float32 // frames per second
+	FrameCounter float32 // seconds spent on the current frame
+	Mode         PlayMode
+	Pivot        rl.Vector2 // point in the frame drawn at the owner's position
+	Finished     bool
+	Events       map[int32][]string // event names fired when a frame is reached
+	listeners    map[string][]func()
+	started      bool
+	direction    int32
+	handle       *assets.TextureHandle
 }
 
-// loadAnimation loads an animation from a texture file
+// loadAnimation loads a looping animation of square frames from a texture file
 func LoadAnimation(filePath string, frameSpeed float32, frameSize int32) Animation {
-	texture := rl.LoadTexture(filePath)
-	frames := texture.Width / frameSize
-	frameWidth := float32(texture.Width) / float32(frames)
-	frameHeight := float32(texture.Height)
-
-	return Animation{
-		Texture:      texture,
-		Frames:       frames,
-		FrameRec:     rl.Rectangle{X: 0, Y: 0, Width: frameWidth, Height: frameHeight},
-		CurrentFrame: 0,
-		FrameSpeed:   frameSpeed,
-		FrameCounter: 0,
+	return LoadAnimationFrames(filePath, frameSpeed, frameSize, frameSize, 0)
+}
+
+// LoadAnimationFrames loads a looping animation from a strip or grid of frames,
+// read left to right then top to bottom. frames is worked out from the texture
+// size when zero
+func LoadAnimationFrames(filePath string, frameSpeed float32, frameWidth, frameHeight, frames int32) Animation {
+	handle := assets.Default.LoadTexture(filePath)
+	return NewAnimation(handle, GridFrames(handle.Texture, frameWidth, frameHeight, frames), frameSpeed)
+}
+
+// GridFrames returns the rectangles of the first frames cells of a texture cut
+// into a grid, every cell when frames is zero
+func GridFrames(texture rl.Texture2D, frameWidth, frameHeight, frames int32) []rl.Rectangle {
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return nil
+	}
+
+	columns := texture.Width / frameWidth
+	if columns == 0 {
+		return nil
+	}
+	if frames <= 0 {
+		frames = columns * (texture.Height / frameHeight)
+	}
+
+	rects := make([]rl.Rectangle, frames)
+	for i := range rects {
+		rects[i] = rl.Rectangle{
+			X:      float32(int32(i)%columns) * float32(frameWidth),
+			Y:      float32(int32(i)/columns) * float32(frameHeight),
+			Width:  float32(frameWidth),
+			Height: float32(frameHeight),
+		}
+	}
+	return rects
+}
+
+// NewAnimation creates a looping animation from frames that can sit anywhere in
+// the texture, taking ownership of the handle
+func NewAnimation(handle *assets.TextureHandle, frameRects []rl.Rectangle, frameSpeed float32) Animation {
+	animation := Animation{
+		Texture:    handle.Texture,
+		Frames:     int32(len(frameRects)),
+		FrameRects: frameRects,
+		FrameSpeed: frameSpeed,
+		Mode:       Loop,
+		handle:     handle,
+	}
+	animation.Reset()
+	return animation
+}
+
+// Unload releases the animation's texture
+func (a *Animation) Unload() {
+	a.handle.Release()
+}
+
+// AddEvent attaches a named event to a frame, fired each time the animation reaches it
+func (a *Animation) AddEvent(frame int32, name string) {
+	if a.Events == nil {
+		a.Events = map[int32][]string{}
+	}
+	a.Events[frame] = append(a.Events[frame], name)
+}
+
+// On subscribes to a named frame event
+func (a *Animation) On(name string, callback func()) {
+	if a.listeners == nil {
+		a.listeners = map[string][]func(){}
+	}
+	a.listeners[name] = append(a.listeners[name], callback)
+}
+
+// fireEvents calls the listeners of every event on a frame
+func (a *Animation) fireEvents(frame int32) {
+	for _, name := range a.Events[frame] {
+		for _, callback := range a.listeners[name] {
+			callback()
+		}
+	}
+}
+
+// frameDuration returns how many seconds a frame is shown for
+func (a *Animation) frameDuration(frame int32) float32 {
+	if int(frame) < len(a.Durations) && a.Durations[frame] > 0 {
+		return a.Durations[frame]
+	}
+	if a.FrameSpeed <= 0 {
+		return 0
+	}
+	return 1 / a.FrameSpeed
+}
+
+// Update advances the animation by dt seconds and reports whether it completed a
+// cycle: wrapped, bounced back to its first frame, or for once-only animations
+// finished. Events on every frame reached along the way are fired. A
+// once-only animation with no frames, like one missing from a sprite manifest,
+// finishes straight away so nothing waits on it forever
+func (a *Animation) Update(dt float32) bool {
+	if a.Finished {
+		return false
+	}
+	if a.Frames == 0 {
+		a.Finished = a.Mode == Once
+		return a.Finished
+	}
+
+	// The first frame's events fire on the first update after a reset
+	if !a.started {
+		a.started = true
+		a.fireEvents(a.CurrentFrame)
+	}
+
+	completed := false
+
+	a.FrameCounter += dt
+	for {
+		duration := a.frameDuration(a.CurrentFrame)
+		if duration <= 0 || a.FrameCounter < duration {
+			break
+		}
+		a.FrameCounter -= duration
+
+		if a.advance() {
+			completed = true
+		}
+		if a.Finished {
+			a.FrameCounter = 0
+			break
+		}
+		a.fireEvents(a.CurrentFrame)
+	}
+	a.setFrameRec()
+
+	return completed
+}
+
+// advance moves to the next frame for the play mode, reporting whether a cycle completed
+func (a *Animation) advance() bool {
+	last := a.Frames - 1
+
+	switch a.Mode {
+	case Once:
+		if a.CurrentFrame >= last {
+			a.Finished = true
+			return true
+		}
+		a.CurrentFrame++
+	case Reverse:
+		a.CurrentFrame--
+		if a.CurrentFrame < 0 {
+			a.CurrentFrame = last
+			return true
+		}
+	case PingPong:
+		if last == 0 {
+			return true
+		}
+		if next := a.CurrentFrame + a.direction; next < 0 || next > last {
+			a.direction = -a.direction
+		}
+		a.CurrentFrame += a.direction
+		return a.CurrentFrame == 0
+	default:
+		a.CurrentFrame++
+		if a.CurrentFrame > last {
+			a.CurrentFrame = 0
+			return true
+		}
+	}
+	return false
+}
+
+// setFrameRec points FrameRec at the current frame
+func (a *Animation) setFrameRec() {
+	if int(a.CurrentFrame) < len(a.FrameRects) {
+		a.FrameRec = a.FrameRects[a.CurrentFrame]
+	}
+}
+
+// Origin returns the point in the current frame drawn at the owner's position,
+// which is the pivot moved by the frame's trim offset
+func (a *Animation) Origin() rl.Vector2 {
+	if int(a.CurrentFrame) < len(a.Offsets) {
+		return rl.Vector2Subtract(a.Pivot, a.Offsets[a.CurrentFrame])
+	}
+	return a.Pivot
+}
+
+// Reset rewinds the animation to its first frame, or its last when playing in reverse
+func (a *Animation) Reset() {
+	a.CurrentFrame = 0
+	if a.Mode == Reverse && a.Frames > 0 {
+		a.CurrentFrame = a.Frames - 1
 	}
+	a.FrameCounter = 0
+	a.direction = 1
+	a.Finished = false
+	a.started = false
+	a.setFrameRec()
 }
@@ -0,0 +1,143 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grcatterall/go-game/classes/assets"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// asepriteRect is a rectangle as written by Aseprite's JSON export
+type asepriteRect struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+// asepriteFrame is one packed frame, Duration is in milliseconds
+type asepriteFrame struct {
+	Frame            asepriteRect `json:"frame"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	Duration         int32        `json:"duration"`
+}
+
+// asepriteTag is a named range of frames, Direction is forward, reverse, pingpong or pingpong_reverse
+type asepriteTag struct {
+	Name      string `json:"name"`
+	From      int32  `json:"from"`
+	To        int32  `json:"to"`
+	Direction string `json:"direction"`
+}
+
+// asepriteSheet is the part of an Aseprite JSON export the game reads
+type asepriteSheet struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string        `json:"image"`
+		FrameTags []asepriteTag `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// LoadAsepriteAnimation loads an animation from a sheet exported by Aseprite with
+// its JSON data, in either the hash or array layout. tag picks a range of frames
+// and its direction, an empty tag plays every frame forwards
+func LoadAsepriteAnimation(jsonPath string, tag string) (Animation, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return Animation{}, err
+	}
+
+	var sheet asepriteSheet
+	if err := json.Unmarshal(data, &sheet); err != nil {
+		return Animation{}, fmt.Errorf("parsing %s: %w", jsonPath, err)
+	}
+
+	frames, err := decodeAsepriteFrames(sheet.Frames)
+	if err != nil {
+		return Animation{}, fmt.Errorf("parsing %s frames: %w", jsonPath, err)
+	}
+
+	from, to, mode := int32(0), int32(len(frames))-1, Loop
+	if tag != "" {
+		found := false
+		for _, frameTag := range sheet.Meta.FrameTags {
+			if frameTag.Name != tag {
+				continue
+			}
+			from, to, found = frameTag.From, frameTag.To, true
+			switch frameTag.Direction {
+			case "reverse":
+				mode = Reverse
+			case "pingpong", "pingpong_reverse":
+				mode = PingPong
+			}
+			break
+		}
+		if !found {
+			return Animation{}, fmt.Errorf("%s has no tag %q", jsonPath, tag)
+		}
+	}
+	if from < 0 || to >= int32(len(frames)) || from > to {
+		return Animation{}, fmt.Errorf("%s tag %q: frames %d-%d out of range", jsonPath, tag, from, to)
+	}
+
+	var frameRects []rl.Rectangle
+	var durations []float32
+	var offsets []rl.Vector2
+	for _, frame := range frames[from : to+1] {
+		frameRects = append(frameRects, rl.Rectangle{
+			X:      float32(frame.Frame.X),
+			Y:      float32(frame.Frame.Y),
+			Width:  float32(frame.Frame.W),
+			Height: float32(frame.Frame.H),
+		})
+		durations = append(durations, float32(frame.Duration)/1000)
+		offsets = append(offsets, rl.Vector2{X: float32(frame.SpriteSourceSize.X), Y: float32(frame.SpriteSourceSize.Y)})
+	}
+
+	handle := assets.Default.LoadTexture(filepath.Join(filepath.Dir(jsonPath), sheet.Meta.Image))
+	animation := NewAnimation(handle, frameRects, 0)
+	animation.Durations = durations
+	animation.Offsets = offsets
+	animation.Mode = mode
+	animation.Reset()
+
+	return animation, nil
+}
+
+// decodeAsepriteFrames reads the frames of either export layout in file order,
+// which a plain map would lose for the hash layout
+func decodeAsepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	var frames []asepriteFrame
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object or array of frames")
+	}
+	for decoder.More() {
+		// Skip the frame's name
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		var frame asepriteFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,157 @@
+package helpers
+
+// AnyState matches every state as the source of a transition
+const AnyState = "*"
+
+// AnimationState is a named animation in a state machine. One-shot states play
+// through once and can only be interrupted by states with a higher priority
+type AnimationState struct {
+	Name      string
+	Animation *Animation
+	OneShot   bool
+	Priority  int
+}
+
+// Transition moves from one state to another when its condition holds,
+// cross-fading over Fade seconds
+type Transition struct {
+	From      string
+	To        string
+	Fade      float32
+	Condition func() bool
+}
+
+// AnimationStateMachine picks which animation plays from declared states and
+// transitions, instead of a hand-written priority switch
+type AnimationStateMachine struct {
+	States      map[string]*AnimationState
+	Transitions []Transition
+	OnFinished  func(state string)
+	current     *AnimationState
+	previous    *AnimationState
+	fade        float32
+	fadeElapsed float32
+}
+
+// NewAnimationStateMachine creates an empty state machine
+func NewAnimationStateMachine() *AnimationStateMachine {
+	return &AnimationStateMachine{States: map[string]*AnimationState{}}
+}
+
+// AddState declares a state, the first state added is the one the machine starts in
+func (sm *AnimationStateMachine) AddState(name string, animation *Animation, oneShot bool, priority int) {
+	state := &AnimationState{
+		Name:      name,
+		Animation: animation,
+		OneShot:   oneShot,
+		Priority:  priority,
+	}
+	sm.States[name] = state
+
+	// One-shot states hold their last frame rather than wrapping
+	if oneShot {
+		animation.Mode = Once
+	}
+
+	if sm.current == nil {
+		sm.enter(state, 0)
+	}
+}
+
+// AddTransition declares a transition, earlier transitions win when several conditions hold
+func (sm *AnimationStateMachine) AddTransition(from, to string, fade float32, condition func() bool) {
+	sm.Transitions = append(sm.Transitions, Transition{
+		From:      from,
+		To:        to,
+		Fade:      fade,
+		Condition: condition,
+	})
+}
+
+// Update follows the first transition whose condition holds and then advances
+// the current animation by dt seconds
+func (sm *AnimationStateMachine) Update(dt float32) {
+	if sm.current == nil {
+		return
+	}
+
+	for _, transition := range sm.Transitions {
+		if transition.From != AnyState && transition.From != sm.current.Name {
+			continue
+		}
+
+		target, ok := sm.States[transition.To]
+		if !ok || !sm.canEnter(target) || !transition.Condition() {
+			continue
+		}
+
+		sm.enter(target, transition.Fade)
+		break
+	}
+
+	if sm.fadeElapsed < sm.fade {
+		sm.fadeElapsed += dt
+	}
+
+	if sm.current.Animation.Update(dt) && sm.current.OneShot {
+		sm.current.Animation.Finished = true
+		if sm.OnFinished != nil {
+			sm.OnFinished(sm.current.Name)
+		}
+	}
+}
+
+// Play jumps straight to a state, ignoring transitions and priorities
+func (sm *AnimationStateMachine) Play(name string) {
+	if state, ok := sm.States[name]; ok {
+		sm.enter(state, 0)
+	}
+}
+
+// Current returns the name of the state being played
+func (sm *AnimationStateMachine) Current() string {
+	if sm.current == nil {
+		return ""
+	}
+	return sm.current.Name
+}
+
+// Animation returns the animation of the state being played
+func (sm *AnimationStateMachine) Animation() *Animation {
+	if sm.current == nil {
+		return nil
+	}
+	return sm.current.Animation
+}
+
+// CrossFade returns the animation being faded out and how far the fade has got,
+// from 0 just switched to 1 done. Nothing is blended here, renderers decide how to use it
+func (sm *AnimationStateMachine) CrossFade() (*Animation, float32) {
+	if sm.previous == nil || sm.fade <= 0 || sm.fadeElapsed >= sm.fade {
+		return nil, 1
+	}
+	return sm.previous.Animation, sm.fadeElapsed / sm.fade
+}
+
+// canEnter reports whether the machine may switch to target right now
+func (sm *AnimationStateMachine) canEnter(target *AnimationState) bool {
+	playing := sm.current.OneShot && !sm.current.Animation.Finished
+
+	if target == sm.current {
+		// Only a finished one-shot state restarts itself
+		return sm.current.OneShot && !playing
+	}
+
+	if playing {
+		return target.Priority > sm.current.Priority
+	}
+	return true
+}
+
+func (sm *AnimationStateMachine) enter(state *AnimationState, fade float32) {
+	sm.previous = sm.current
+	sm.current = state
+	sm.fade = fade
+	sm.fadeElapsed = 0
+	state.Animation.Reset()
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// testAnimation is an animation with a number of frames shown 10 a second, and no texture
+func testAnimation(frames int) *Animation {
+	rects := make([]rl.Rectangle, frames)
+	for i := range rects {
+		rects[i] = rl.NewRectangle(float32(i*16), 0, 16, 16)
+	}
+	animation := &Animation{Frames: int32(frames), FrameRects: rects, FrameSpeed: 10}
+	animation.Reset()
+	return animation
+}
+
+// hurtMachine is an idle state interrupted by a one-shot hurt state while
+// hurt is set, clearing it when the hurt animation finishes like the player's
+func hurtMachine(hurtFrames int, hurt *bool) *AnimationStateMachine {
+	sm := NewAnimationStateMachine()
+	sm.AddState("idle", testAnimation(4), false, 0)
+	sm.AddState("hurt", testAnimation(hurtFrames), true, 3)
+	sm.AddTransition(AnyState, "hurt", 0, func() bool { return *hurt })
+	sm.AddTransition("hurt", "idle", 0, func() bool { return !*hurt })
+	sm.OnFinished = func(state string) {
+		if state == "hurt" {
+			*hurt = false
+		}
+	}
+	return sm
+}
+
+func TestOneShotStateFinishes(t *testing.T) {
+	hurt := true
+	sm := hurtMachine(3, &hurt)
+
+	sm.Update(0.05)
+	if sm.Current() != "hurt" {
+		t.Fatalf("playing %q, want hurt", sm.Current())
+	}
+	for i := 0; i < 10 && sm.Current() != "idle"; i++ {
+		sm.Update(0.1)
+	}
+	if hurt || sm.Current() != "idle" {
+		t.Errorf("hurt state never finished: playing %q, hurt %v", sm.Current(), hurt)
+	}
+}
+
+func TestOneShotStateWithNoFramesFinishes(t *testing.T) {
+	hurt := true
+	sm := hurtMachine(0, &hurt)
+
+	sm.Update(0.1)
+	sm.Update(0.1)
+	if hurt || sm.Current() != "idle" {
+		t.Errorf("a hurt animation with no frames got stuck: playing %q, hurt %v", sm.Current(), hurt)
+	}
+}
+
+func TestLoopWithNoFramesNeverCompletes(t *testing.T) {
+	animation := testAnimation(0)
+	for i := 0; i < 3; i++ {
+		if animation.Update(1) || animation.Finished {
+			t.Fatal("a looping animation with no frames reported completing")
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package helpers
+
+// FixedTimestep hands out real frame time as a whole number of fixed-size
+// simulation ticks so gameplay speed does not depend on the render rate
+type FixedTimestep struct {
+	TickRate     float32
+	MaxFrameTime float32
+	accumulator  float32
+}
+
+// NewFixedTimestep creates a timestep running at tickRate ticks per second
+func NewFixedTimestep(tickRate float32) *FixedTimestep {
+	return &FixedTimestep{
+		TickRate:     tickRate,
+		MaxFrameTime: 0.25,
+		accumulator:  0,
+	}
+}
+
+// Delta returns the length of a single tick in seconds
+func (ft *FixedTimestep) Delta() float32 {
+	return 1 / ft.TickRate
+}
+
+// Advance adds a frame's elapsed time and returns how many ticks to simulate
+func (ft *FixedTimestep) Advance(frameTime float32) int {
+	// Clamp long frames (window drags, breakpoints) so we don't spiral trying to catch up
+	if frameTime > ft.MaxFrameTime {
+		frameTime = ft.MaxFrameTime
+	}
+
+	ft.accumulator += frameTime
+
+	ticks := 0
+	delta := ft.Delta()
+	for ft.accumulator >= delta {
+		ft.accumulator -= delta
+		ticks++
+	}
+	return ticks
+}
+
+// Alpha returns how far the current frame sits between the last tick and the next,
+// used to interpolate render positions
+func (ft *FixedTimestep) Alpha() float32 {
+	return ft.accumulator / ft.Delta()
+}
+
+// Lerp linearly interpolates between from and to by t
+func Lerp(from, to, t float32) float32 {
+	return from + (to-from)*t
+}
@@ -0,0 +1,69 @@
+package input
+
+// Action is a named thing the player can do, independent of the key or button bound to it
+type Action int
+
+const (
+	MoveLeft Action = iota
+	MoveRight
+	Jump
+	Sprint
+	Shoot
+	Melee
+	Pause
+	Confirm
+	Back
+	MoveDown
+	Debug
+	Editor
+	ReplayFaster
+	ReplaySlower
+	SeekForward
+	SeekBack
+	actionCount
+)
+
+var actionNames = [actionCount]string{
+	MoveLeft:     "MoveLeft",
+	MoveRight:    "MoveRight",
+	Jump:         "Jump",
+	Sprint:       "Sprint",
+	Shoot:        "Shoot",
+	Melee:        "Melee",
+	Pause:        "Pause",
+	Confirm:      "Confirm",
+	Back:         "Back",
+	MoveDown:     "MoveDown",
+	Debug:        "Debug",
+	Editor:       "Editor",
+	ReplayFaster: "ReplayFaster",
+	ReplaySlower: "ReplaySlower",
+	SeekForward:  "SeekForward",
+	SeekBack:     "SeekBack",
+}
+
+// Actions returns every action in declaration order
+func Actions() []Action {
+	actions := make([]Action, actionCount)
+	for i := range actions {
+		actions[i] = Action(i)
+	}
+	return actions
+}
+
+func (a Action) String() string {
+	if a < 0 || a >= actionCount {
+		return "Unknown"
+	}
+	return actionNames[a]
+}
+
+// ParseAction looks up an action by the name used in config files
+func ParseAction(name string) (Action, bool) {
+	for i, actionName := range actionNames {
+		if actionName == name {
+			return Action(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Device is the kind of hardware a binding reads from
+type Device string
+
+const (
+	Keyboard      Device = "key"
+	Mouse         Device = "mouse"
+	GamepadButton Device = "gamepad"
+	GamepadAxis   Device = "axis"
+)
+
+// Binding ties an action to a single key, mouse button, gamepad button or gamepad axis.
+// Axis bindings are down once the axis passes Threshold, in the direction of its sign
+type Binding struct {
+	Device    Device  `json:"device"`
+	Code      int32   `json:"code"`
+	Threshold float32 `json:"threshold,omitempty"`
+}
+
+// Bindings maps every action to the inputs that trigger it
+type Bindings map[Action][]Binding
+
+// Key creates a keyboard binding
+func Key(code int32) Binding {
+	return Binding{Device: Keyboard, Code: code}
+}
+
+// MouseButton creates a mouse button binding
+func MouseButton(code int32) Binding {
+	return Binding{Device: Mouse, Code: code}
+}
+
+// Button creates a gamepad button binding
+func Button(code int32) Binding {
+	return Binding{Device: GamepadButton, Code: code}
+}
+
+// Axis creates a gamepad axis binding that is down past threshold,
+// use a negative threshold for the negative direction of a stick
+func Axis(code int32, threshold float32) Binding {
+	return Binding{Device: GamepadAxis, Code: code, Threshold: threshold}
+}
+
+// DefaultBindings returns the keyboard, mouse and gamepad layout the game ships with.
+// Left stick movement is handled by the manager rather than through bindings
+func DefaultBindings() Bindings {
+	return Bindings{
+		MoveLeft:     {Key(rl.KeyA), Button(rl.GamepadButtonLeftFaceLeft)},
+		MoveRight:    {Key(rl.KeyD), Button(rl.GamepadButtonLeftFaceRight)},
+		Jump:         {Key(rl.KeySpace), Button(rl.GamepadButtonRightFaceDown)},
+		Sprint:       {Key(rl.KeyLeftShift), Button(rl.GamepadButtonLeftTrigger1)},
+		Shoot:        {MouseButton(rl.MouseButtonLeft), Axis(rl.GamepadAxisRightTrigger, 0.5)},
+		Melee:        {MouseButton(rl.MouseButtonRight), Button(rl.GamepadButtonRightFaceLeft)},
+		Pause:        {Key(rl.KeyP), Button(rl.GamepadButtonMiddleRight)},
+		Confirm:      {Key(rl.KeyEnter), Button(rl.GamepadButtonRightFaceDown)},
+		Back:         {Key(rl.KeyQ), Button(rl.GamepadButtonRightFaceRight)},
+		MoveDown:     {Key(rl.KeyS), Button(rl.GamepadButtonLeftFaceDown)},
+		Debug:        {Key(rl.KeyF3)},
+		Editor:       {Key(rl.KeyF1)},
+		ReplayFaster: {Key(rl.KeyUp)},
+		ReplaySlower: {Key(rl.KeyDown)},
+		SeekForward:  {Key(rl.KeyRight)},
+		SeekBack:     {Key(rl.KeyLeft)},
+	}
+}
+
+// Clone returns a deep copy so callers can edit bindings without affecting the original
+func (b Bindings) Clone() Bindings {
+	clone := make(Bindings, len(b))
+	for action, bindings := range b {
+		clone[action] = append([]Binding(nil), bindings...)
+	}
+	return clone
+}
+
+// MarshalJSON writes bindings keyed by action name so config files stay readable
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	named := make(map[string][]Binding, len(b))
+	for action, bindings := range b {
+		named[action.String()] = bindings
+	}
+	return json.Marshal(named)
+}
+
+// UnmarshalJSON reads bindings keyed by action name
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	var named map[string][]Binding
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+
+	bindings := make(Bindings, len(named))
+	for name, actionBindings := range named {
+		action, ok := ParseAction(name)
+		if !ok {
+			return fmt.Errorf("unknown input action %q", name)
+		}
+		bindings[action] = actionBindings
+	}
+	*b = bindings
+	return nil
+}
+
+// LoadBindings reads bindings from a JSON config file
+func LoadBindings(path string) (Bindings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var bindings Bindings
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return bindings, nil
+}
+
+// SaveBindings writes bindings to a JSON config file, creating its directory if needed
+func SaveBindings(path string, bindings Bindings) error {
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
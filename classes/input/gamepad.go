@@ -0,0 +1,93 @@
+package input
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// maxGamepads is how many controller slots are scanned when looking for a pad
+const maxGamepads = 4
+
+// updateGamepad finds a connected controller, switching to another slot when
+// the current one is unplugged, and reports whether one is in use
+func (m *Manager) updateGamepad() bool {
+	connected := rl.IsGamepadAvailable(m.Gamepad)
+	if !connected {
+		for gamepad := int32(0); gamepad < maxGamepads; gamepad++ {
+			if rl.IsGamepadAvailable(gamepad) {
+				m.Gamepad = gamepad
+				connected = true
+				break
+			}
+		}
+	}
+
+	if connected != m.gamepadConnected {
+		m.gamepadConnected = connected
+		if m.OnGamepadChange != nil {
+			name := ""
+			if connected {
+				name = rl.GetGamepadName(m.Gamepad)
+			}
+			m.OnGamepadChange(connected, name)
+		}
+	}
+
+	return connected
+}
+
+// GamepadConnected reports whether a controller was found on the last poll
+func (m *Manager) GamepadConnected() bool {
+	return m.gamepadConnected
+}
+
+// stickActions turns the left stick into movement actions, walking past the
+// deadzone and sprinting once pushed past SprintThreshold
+func (m *Manager) stickActions() uint32 {
+	x, y := applyDeadzone(
+		rl.GetGamepadAxisMovement(m.Gamepad, rl.GamepadAxisLeftX),
+		rl.GetGamepadAxisMovement(m.Gamepad, rl.GamepadAxisLeftY),
+		m.Deadzone,
+	)
+
+	var actions uint32
+	if x < 0 {
+		actions |= bit(MoveLeft)
+	} else if x > 0 {
+		actions |= bit(MoveRight)
+	}
+
+	// Only a mostly downward push counts, so running diagonally doesn't drop through platforms
+	if y > 0 && y > float32(math.Abs(float64(x))) {
+		actions |= bit(MoveDown)
+	}
+
+	if float32(math.Hypot(float64(x), float64(y))) >= m.SprintThreshold {
+		actions |= bit(Sprint)
+	}
+	return actions
+}
+
+// applyDeadzone zeroes stick readings inside a radial deadzone and rescales
+// the rest so movement starts from zero at the deadzone's edge
+func applyDeadzone(x, y, deadzone float32) (float32, float32) {
+	magnitude := float32(math.Hypot(float64(x), float64(y)))
+	if magnitude <= deadzone {
+		return 0, 0
+	}
+
+	scaled := (magnitude - deadzone) / (1 - deadzone)
+	if scaled > 1 {
+		scaled = 1
+	}
+	return x / magnitude * scaled, y / magnitude * scaled
+}
+
+func (m *Manager) isAxisDown(binding Binding) bool {
+	value := rl.GetGamepadAxisMovement(m.Gamepad, binding.Code)
+	if binding.Threshold < 0 {
+		return value <= binding.Threshold
+	}
+	return value >= binding.Threshold
+}
@@ -0,0 +1,115 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Manager polls the keyboard, mouse and gamepad once per tick and turns them into action state
+type Manager struct {
+	Bindings         Bindings
+	Gamepad          int32
+	Deadzone         float32
+	SprintThreshold  float32
+	OnGamepadChange  func(connected bool, name string)
+	gamepadConnected bool
+	state            State
+}
+
+// Default is the manager shared by the game loop, scenes and the player
+var Default = NewManager(DefaultBindings())
+
+// NewManager creates an input manager using the given bindings
+func NewManager(bindings Bindings) *Manager {
+	return &Manager{
+		Bindings:        bindings,
+		Gamepad:         0,
+		Deadzone:        0.2,
+		SprintThreshold: 0.8,
+	}
+}
+
+// Poll reads the devices for a new tick, call it once before each simulation step
+func (m *Manager) Poll() {
+	var current uint32
+
+	gamepadConnected := m.updateGamepad()
+	if gamepadConnected {
+		current |= m.stickActions()
+	}
+
+	for action, bindings := range m.Bindings {
+		for _, binding := range bindings {
+			if m.isDown(binding, gamepadConnected) {
+				current |= bit(action)
+				break
+			}
+		}
+	}
+	m.state.Advance(current)
+}
+
+// State returns a snapshot of this tick's action state
+func (m *Manager) State() State {
+	return m.state
+}
+
+// Held reports whether the action is down this tick
+func (m *Manager) Held(action Action) bool {
+	return m.state.Held(action)
+}
+
+// Pressed reports whether the action went down this tick
+func (m *Manager) Pressed(action Action) bool {
+	return m.state.Pressed(action)
+}
+
+// Released reports whether the action came up this tick
+func (m *Manager) Released(action Action) bool {
+	return m.state.Released(action)
+}
+
+// Rebind replaces every binding for an action
+func (m *Manager) Rebind(action Action, bindings ...Binding) {
+	m.Bindings[action] = bindings
+}
+
+// AddBinding adds another input that triggers an action
+func (m *Manager) AddBinding(action Action, binding Binding) {
+	m.Bindings[action] = append(m.Bindings[action], binding)
+}
+
+// ListenForBinding returns whichever key or button was pressed this frame,
+// for use by a rebinding menu
+func (m *Manager) ListenForBinding() (Binding, bool) {
+	if key := rl.GetKeyPressed(); key != 0 {
+		return Key(key), true
+	}
+
+	for button := int32(rl.MouseButtonLeft); button <= rl.MouseButtonBack; button++ {
+		if rl.IsMouseButtonPressed(button) {
+			return MouseButton(button), true
+		}
+	}
+
+	if m.gamepadConnected {
+		if button := rl.GetGamepadButtonPressed(); button != rl.GamepadButtonUnknown {
+			return Button(button), true
+		}
+	}
+
+	return Binding{}, false
+}
+
+func (m *Manager) isDown(binding Binding, gamepadConnected bool) bool {
+	switch binding.Device {
+	case Keyboard:
+		return rl.IsKeyDown(binding.Code)
+	case Mouse:
+		return rl.IsMouseButtonDown(binding.Code)
+	case GamepadButton:
+		return gamepadConnected && rl.IsGamepadButtonDown(m.Gamepad, binding.Code)
+	case GamepadAxis:
+		return gamepadConnected && m.isAxisDown(binding)
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package input
+
+// Controller is the read side of input that gameplay code depends on, so it
+// can be driven by real devices, a replay or a test without a window
+type Controller interface {
+	Held(action Action) bool
+	Pressed(action Action) bool
+	Released(action Action) bool
+}
+
+// State holds which actions are down this tick and which were down last tick
+type State struct {
+	Current  uint32
+	Previous uint32
+}
+
+// Held reports whether the action is down this tick
+func (s *State) Held(action Action) bool {
+	return s.Current&bit(action) != 0
+}
+
+// Pressed reports whether the action went down this tick
+func (s *State) Pressed(action Action) bool {
+	return s.Current&bit(action) != 0 && s.Previous&bit(action) == 0
+}
+
+// Released reports whether the action came up this tick
+func (s *State) Released(action Action) bool {
+	return s.Current&bit(action) == 0 && s.Previous&bit(action) != 0
+}
+
+// Set marks an action as down or up for the current tick
+func (s *State) Set(action Action, down bool) {
+	if down {
+		s.Current |= bit(action)
+	} else {
+		s.Current &^= bit(action)
+	}
+}
+
+// Advance starts a new tick, carrying the current actions over as the previous ones
+func (s *State) Advance(current uint32) {
+	s.Previous = s.Current
+	s.Current = current
+}
+
+func bit(action Action) uint32 {
+	return 1 << uint(action)
+}
@@ -0,0 +1,50 @@
+package pickups
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Health pickups give the player back some health
+const Health = "health"
+
+// pickupSize is the width and height of a pickup in pixels
+const pickupSize = 12
+
+type Pickup struct {
+	Position  rl.Vector2 // the pickup's bottom centre, so it sits on the ground it is placed on
+	Kind      string
+	Amount    int32
+	Collected bool
+}
+
+// NewPickup creates a pickup of the given kind, amount is how much it gives
+func NewPickup(kind string, amount int32, position rl.Vector2) *Pickup {
+	if amount <= 0 {
+		amount = 1
+	}
+	return &Pickup{
+		Position: position,
+		Kind:     kind,
+		Amount:   amount,
+	}
+}
+
+// Bounds returns the area the player has to touch to collect the pickup
+func (p *Pickup) Bounds() rl.Rectangle {
+	return rl.NewRectangle(p.Position.X-pickupSize/2, p.Position.Y-pickupSize, pickupSize, pickupSize)
+}
+
+// Draw renders the pickup as a coloured square until it is collected
+func (p *Pickup) Draw() {
+	if p.Collected {
+		return
+	}
+
+	colour := rl.SkyBlue
+	if p.Kind == Health {
+		colour = rl.Red
+	}
+	bounds := p.Bounds()
+	rl.DrawRectangleRec(bounds, colour)
+	rl.DrawRectangleLinesEx(bounds, 1, rl.White)
+}
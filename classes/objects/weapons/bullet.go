@@ -1,49 +1,58 @@
 package weapons
 
 import (
+	"github.com/grcatterall/go-game/classes/helpers"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 type Bullet struct {
-	Position rl.Vector2
-	Speed    rl.Vector2
-	Active   bool
-	Width    float32
-	Height   float32
+	Position         rl.Vector2
+	PreviousPosition rl.Vector2
+	Speed            rl.Vector2 // pixels per second
+	Active           bool
+	Width            float32
+	Height           float32
+	Lifetime         float32 // seconds left before the bullet expires
 }
 
+// bulletLifetime is how long a bullet flies before it is removed
+const bulletLifetime = 1.0
+
 // NewBullet creates a new bullet instance
 func NewBullet(position, speed rl.Vector2, width, height float32) *Bullet {
 	return &Bullet{
-		Position: position,
-		Speed:    speed,
-		Active:   true,
-		Width:    width,
-		Height:   height,
+		Position:         position,
+		PreviousPosition: position,
+		Speed:            speed,
+		Active:           true,
+		Width:            width,
+		Height:           height,
+		Lifetime:         bulletLifetime,
 	}
 }
 
-// Update updates the bullet's position and active status
-func (b *Bullet) Update() {
+// Update moves the bullet by dt seconds and updates its active status
+func (b *Bullet) Update(dt float32) {
 	if b.Active {
-		b.Position.X += b.Speed.X
-		b.Position.Y += b.Speed.Y
+		b.PreviousPosition = b.Position
+		b.Position.X += b.Speed.X * dt
+		b.Position.Y += b.Speed.Y * dt
 
-		b.Draw()
-
-		// Deactivate the bullet if it moves off-screen (example logic)
-		if b.Position.X < 0 || b.Position.X > float32(rl.GetScreenWidth()) ||
-			b.Position.Y < 0 || b.Position.Y > float32(rl.GetScreenHeight()) {
+		// Deactivate the bullet once it has flown well past the screen
+		b.Lifetime -= dt
+		if b.Lifetime <= 0 {
 			b.Active = false
 		}
-
 	}
 }
 
-// Draw renders the bullet as a yellow square
-func (b *Bullet) Draw() {
+// Draw renders the bullet as a yellow square, interpolated alpha of the way between ticks
+func (b *Bullet) Draw(alpha float32) {
 	if b.Active {
-		rl.DrawRectangle(int32(b.Position.X), int32(b.Position.Y), int32(b.Width), int32(b.Height), rl.Yellow)
+		x := helpers.Lerp(b.PreviousPosition.X, b.Position.X, alpha)
+		y := helpers.Lerp(b.PreviousPosition.Y, b.Position.Y, alpha)
+		rl.DrawRectangle(int32(x), int32(y), int32(b.Width), int32(b.Height), rl.Yellow)
 	}
 }
 
@@ -55,7 +64,7 @@ func (b *Bullet) CheckCollision(target rl.Rectangle) bool {
 
 // SpawnBullet spawns a new bullet from the player's position
 func SpawnBullet(position rl.Vector2, isLeft bool) *Bullet {
-	bulletSpeed := rl.Vector2{X: 30, Y: 0} // Example speed, adjust as needed
+	bulletSpeed := rl.Vector2{X: 1800, Y: 0} // Example speed in pixels per second, adjust as needed
 	if isLeft {
 		bulletSpeed.X = -bulletSpeed.X // Reverse direction if facing left
 	}
@@ -0,0 +1,117 @@
+package replay
+
+import (
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/world"
+)
+
+// Desync is a tick where the replayed world no longer matches the recorded one
+type Desync struct {
+	Tick     uint64
+	Expected uint64
+	Actual   uint64
+}
+
+// Playback steps a fresh world through a recording's inputs
+type Playback struct {
+	Recording *Recording
+	World     *world.World
+	Paused    bool
+	Speed     float32
+	Desyncs   []Desync
+	OnDesync  func(desync Desync)
+	input     input.State
+	pending   float32
+	newWorld  func(level int, seed int64) *world.World
+}
+
+// NewPlayback creates a playback of a recording, newWorld builds the starting
+// world and is called again whenever playback has to rewind
+func NewPlayback(recording *Recording, newWorld func(level int, seed int64) *world.World) *Playback {
+	playback := &Playback{
+		Recording: recording,
+		Speed:     1,
+		newWorld:  newWorld,
+	}
+	playback.restart()
+	return playback
+}
+
+// Update plays Speed ticks for every game tick, fractions carry over so slow
+// motion works, and does nothing while paused
+func (p *Playback) Update() {
+	if p.Paused {
+		return
+	}
+
+	p.pending += p.Speed
+	for p.pending >= 1 {
+		p.pending--
+		if !p.Step() {
+			p.pending = 0
+			return
+		}
+	}
+}
+
+// Step plays a single recorded tick, returning false once the recording has ended
+func (p *Playback) Step() bool {
+	if p.Done() {
+		return false
+	}
+
+	p.input.Advance(p.Recording.Inputs[p.World.Tick])
+	p.World.Step(1 / p.Recording.TickRate)
+
+	if expected, ok := p.Recording.ExpectedHash(p.World.Tick); ok {
+		if actual := p.World.StateHash(); actual != expected {
+			desync := Desync{Tick: p.World.Tick, Expected: expected, Actual: actual}
+			p.Desyncs = append(p.Desyncs, desync)
+			if p.OnDesync != nil {
+				p.OnDesync(desync)
+			}
+		}
+	}
+
+	return true
+}
+
+// Seek jumps to a tick, rewinding by replaying from the start when it lies behind us
+func (p *Playback) Seek(tick uint64) {
+	if tick > uint64(len(p.Recording.Inputs)) {
+		tick = uint64(len(p.Recording.Inputs))
+	}
+
+	if tick < p.World.Tick {
+		p.World.Unload()
+		p.restart()
+	}
+
+	for p.World.Tick < tick {
+		p.Step()
+	}
+}
+
+// Tick returns the last tick played
+func (p *Playback) Tick() uint64 {
+	return p.World.Tick
+}
+
+// Length returns the number of ticks in the recording
+func (p *Playback) Length() uint64 {
+	return uint64(len(p.Recording.Inputs))
+}
+
+// Done reports whether every recorded tick has been played
+func (p *Playback) Done() bool {
+	return p.World.Tick >= p.Length()
+}
+
+// restart rebuilds the world from the recording's level and seed, driven by recorded input
+func (p *Playback) restart() {
+	p.World = p.newWorld(p.Recording.Level, p.Recording.Seed)
+	p.World.Player.Input = &p.input
+	p.input = input.State{}
+	p.pending = 0
+	p.Desyncs = nil
+}
@@ -0,0 +1,104 @@
+package replay
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/world"
+)
+
+// Levels, sprite manifests and textures are found from the root of the repository
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	registry, err := levels.LoadRegistry(levels.RegistryFile)
+	if err != nil {
+		panic(err)
+	}
+	levels.Default = registry
+	os.Exit(m.Run())
+}
+
+const tickRate = 60
+
+// script is the input held on a tick: run right, jumping now and then and
+// shooting and sprinting for a while
+func script(tick int) uint32 {
+	state := &input.State{}
+	state.Set(input.MoveRight, tick%200 < 150)
+	state.Set(input.MoveLeft, tick%200 >= 170)
+	state.Set(input.Jump, tick%90 < 10)
+	state.Set(input.Sprint, tick%300 > 120)
+	state.Set(input.Shoot, tick%250 == 60)
+	state.Set(input.Melee, tick%400 == 200)
+	return state.Current
+}
+
+// record plays ticks of the script on a level, recording them
+func record(t *testing.T, level, ticks int) (*Recording, uint64) {
+	t.Helper()
+	w := world.NewWorld(level, 7)
+	defer w.Unload()
+
+	state := &input.State{}
+	w.Player.Input = state
+	recorder := NewRecorder(w, tickRate)
+	for tick := 0; tick < ticks; tick++ {
+		state.Advance(script(tick))
+		w.Step(1.0 / tickRate)
+		recorder.Record(w, *state)
+	}
+	return recorder.Recording, w.StateHash()
+}
+
+func TestPlaybackMatchesRecording(t *testing.T) {
+	const ticks = 900
+	for _, level := range []int{1, 2} {
+		recording, finalHash := record(t, level, ticks)
+		if len(recording.Checkpoints) == 0 {
+			t.Fatalf("level %d: recording has no state hashes", level)
+		}
+
+		playback := NewPlayback(recording, world.NewWorld)
+		for playback.Step() {
+		}
+
+		if len(playback.Desyncs) > 0 {
+			t.Errorf("level %d: %d desyncs, the first at tick %d", level, len(playback.Desyncs), playback.Desyncs[0].Tick)
+		}
+		if playback.Tick() != ticks {
+			t.Errorf("level %d: played %d ticks, want %d", level, playback.Tick(), ticks)
+		}
+		if hash := playback.World.StateHash(); hash != finalHash {
+			t.Errorf("level %d: replay ended with hash %016x, the recording with %016x", level, hash, finalHash)
+		}
+
+		// Rewinding replays from the start and should land on the same state again
+		playback.Seek(ticks / 3)
+		playback.Seek(ticks)
+		if len(playback.Desyncs) > 0 || playback.World.StateHash() != finalHash {
+			t.Errorf("level %d: seeking back and forth desynced", level)
+		}
+		playback.World.Unload()
+	}
+}
+
+func TestPlaybackReportsDesyncs(t *testing.T) {
+	recording, _ := record(t, 1, 300)
+
+	// Replaying different input has to show up as a desync
+	for i := range recording.Inputs {
+		recording.Inputs[i] = 0
+	}
+	playback := NewPlayback(recording, world.NewWorld)
+	defer playback.World.Unload()
+	for playback.Step() {
+	}
+
+	if len(playback.Desyncs) == 0 {
+		t.Error("replaying other input reported no desyncs")
+	}
+}
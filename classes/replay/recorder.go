@@ -0,0 +1,35 @@
+package replay
+
+import (
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/world"
+)
+
+// DefaultHashInterval is how many ticks pass between recorded state hashes
+const DefaultHashInterval = 30
+
+// Recorder captures the input of every tick of a world, plus periodic state hashes
+type Recorder struct {
+	Recording *Recording
+}
+
+// NewRecorder starts a recording of a world that has not been stepped yet
+func NewRecorder(w *world.World, tickRate float32) *Recorder {
+	return &Recorder{
+		Recording: &Recording{
+			Level:        w.Level,
+			Seed:         w.Seed,
+			TickRate:     tickRate,
+			HashInterval: DefaultHashInterval,
+		},
+	}
+}
+
+// Record stores the input used for the tick the world just stepped, call it after World.Step
+func (r *Recorder) Record(w *world.World, state input.State) {
+	r.Recording.Inputs = append(r.Recording.Inputs, state.Current)
+
+	if w.Tick%r.Recording.HashInterval == 0 {
+		r.Recording.Checkpoints = append(r.Recording.Checkpoints, Checkpoint{Tick: w.Tick, Hash: w.StateHash()})
+	}
+}
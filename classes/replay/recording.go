@@ -0,0 +1,200 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// fileMagic starts every recording file
+var fileMagic = []byte("GGRP")
+
+// fileVersion is bumped whenever the recording layout changes
+const fileVersion = 1
+
+// Checkpoint is the world state hash taken after a given tick
+type Checkpoint struct {
+	Tick uint64
+	Hash uint64
+}
+
+// Recording is everything needed to replay a run: the level and seed it
+// started from, the input of every tick and periodic state hashes. Endless
+// runs play generated levels, EndlessSeed being the seed they were generated from
+type Recording struct {
+	Level        int
+	Seed         int64
+	Endless      bool
+	EndlessSeed  int64
+	TickRate     float32
+	HashInterval uint64
+	Inputs       []uint32
+	Checkpoints  []Checkpoint
+}
+
+// ExpectedHash returns the hash recorded after a tick, if one was taken
+func (r *Recording) ExpectedHash(tick uint64) (uint64, bool) {
+	for _, checkpoint := range r.Checkpoints {
+		if checkpoint.Tick == tick {
+			return checkpoint.Hash, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalBinary encodes the recording with inputs run-length compressed, since
+// held keys repeat for many ticks in a row
+func (r *Recording) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+
+	writeUvarint := func(value uint64) {
+		buf.Write(scratch[:binary.PutUvarint(scratch[:], value)])
+	}
+
+	buf.Write(fileMagic)
+	writeUvarint(fileVersion)
+	writeUvarint(uint64(r.Level))
+	buf.Write(scratch[:binary.PutVarint(scratch[:], r.Seed)])
+	if r.Endless {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	buf.Write(scratch[:binary.PutVarint(scratch[:], r.EndlessSeed)])
+	writeUvarint(uint64(math.Float32bits(r.TickRate)))
+	writeUvarint(r.HashInterval)
+	writeUvarint(uint64(len(r.Inputs)))
+
+	for i := 0; i < len(r.Inputs); {
+		run := 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run] == r.Inputs[i] {
+			run++
+		}
+		writeUvarint(uint64(run))
+		writeUvarint(uint64(r.Inputs[i]))
+		i += run
+	}
+
+	writeUvarint(uint64(len(r.Checkpoints)))
+	for _, checkpoint := range r.Checkpoints {
+		writeUvarint(checkpoint.Tick)
+		binary.Write(&buf, binary.LittleEndian, checkpoint.Hash)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a recording written by MarshalBinary
+func (r *Recording) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	magic := make([]byte, len(fileMagic))
+	if _, err := reader.Read(magic); err != nil || !bytes.Equal(magic, fileMagic) {
+		return errors.New("not a replay file")
+	}
+
+	version, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	if version != fileVersion {
+		return fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	level, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	seed, err := binary.ReadVarint(reader)
+	if err != nil {
+		return err
+	}
+	endless, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	endlessSeed, err := binary.ReadVarint(reader)
+	if err != nil {
+		return err
+	}
+	tickRate, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	hashInterval, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	tickCount, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+
+	r.Level = int(level)
+	r.Seed = seed
+	r.Endless = endless != 0
+	r.EndlessSeed = endlessSeed
+	r.TickRate = math.Float32frombits(uint32(tickRate))
+	r.HashInterval = hashInterval
+
+	r.Inputs = nil
+	for uint64(len(r.Inputs)) < tickCount {
+		run, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return err
+		}
+		bits, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return err
+		}
+		if run == 0 || run > tickCount-uint64(len(r.Inputs)) {
+			return errors.New("corrupt replay input run")
+		}
+		for j := uint64(0); j < run; j++ {
+			r.Inputs = append(r.Inputs, uint32(bits))
+		}
+	}
+
+	checkpointCount, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	r.Checkpoints = make([]Checkpoint, checkpointCount)
+	for i := range r.Checkpoints {
+		if r.Checkpoints[i].Tick, err = binary.ReadUvarint(reader); err != nil {
+			return err
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &r.Checkpoints[i].Hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save writes the recording to a file
+func (r *Recording) Save(path string) error {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load reads a recording from a file
+func Load(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	recording := &Recording{}
+	if err := recording.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("loading replay %s: %w", path, err)
+	}
+	return recording, nil
+}
@@ -0,0 +1,53 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordingBinaryRoundTrip(t *testing.T) {
+	recording := &Recording{
+		Level:        2,
+		Seed:         -42,
+		Endless:      true,
+		EndlessSeed:  1 << 40,
+		TickRate:     60,
+		HashInterval: DefaultHashInterval,
+		Inputs:       []uint32{0, 0, 0, 1, 1, 5, 0, 1 << 31, 1 << 31},
+		Checkpoints: []Checkpoint{
+			{Tick: 0, Hash: 0xdeadbeef},
+			{Tick: 30, Hash: 1<<64 - 1},
+		},
+	}
+
+	data, err := recording.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Recording
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, recording) {
+		t.Errorf("decoded %+v, want %+v", decoded, *recording)
+	}
+}
+
+func TestRecordingRejectsBadData(t *testing.T) {
+	recording := &Recording{Level: 1, TickRate: 60, HashInterval: 30, Inputs: []uint32{1, 1, 2}}
+	data, err := recording.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"not magic": []byte("NOPE"),
+		"truncated": data[:len(data)-2],
+	} {
+		var decoded Recording
+		if err := decoded.UnmarshalBinary(bad); err == nil {
+			t.Errorf("%s: decoded without an error", name)
+		}
+	}
+}
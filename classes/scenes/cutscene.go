@@ -0,0 +1,58 @@
+package scenes
+
+import (
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// CutsceneScene is an overlay pushed over gameplay when a trigger plays a
+// cutscene, showing its lines one at a time and freezing the level until the last
+type CutsceneScene struct {
+	Lines   []string
+	line    int
+	manager *game_manager.SceneManager
+}
+
+// NewCutsceneScene creates a cutscene showing the given lines
+func NewCutsceneScene(lines []string) *CutsceneScene {
+	return &CutsceneScene{Lines: lines}
+}
+
+func (c *CutsceneScene) Enter(manager *game_manager.SceneManager) {
+	c.manager = manager
+	if len(c.Lines) == 0 {
+		c.manager.Pop()
+	}
+}
+
+func (c *CutsceneScene) Exit() {}
+
+// Update moves on to the next line, returning to the level after the last
+func (c *CutsceneScene) Update(dt float32) {
+	if !input.Default.Pressed(input.Confirm) && !input.Default.Pressed(input.Jump) {
+		return
+	}
+
+	c.line++
+	if c.line >= len(c.Lines) {
+		c.manager.Pop()
+	}
+}
+
+// Draw shows the current line in a letterbox over the level
+func (c *CutsceneScene) Draw(alpha float32) {
+	if c.line >= len(c.Lines) {
+		return
+	}
+
+	screenWidth := int32(rl.GetScreenWidth())
+	screenHeight := int32(rl.GetScreenHeight())
+	barHeight := screenHeight / 6
+
+	rl.DrawRectangle(0, 0, screenWidth, barHeight, rl.Black)
+	rl.DrawRectangle(0, screenHeight-barHeight, screenWidth, barHeight, rl.Black)
+	drawCenteredText(c.Lines[c.line], screenHeight-barHeight+barHeight/2-20, 24, rl.White)
+	drawCenteredText("enter - next", screenHeight-barHeight+barHeight/2+14, 16, rl.Gray)
+}
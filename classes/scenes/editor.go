@@ -0,0 +1,629 @@
+package scenes
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/grcatterall/go-game/classes/characters"
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// editorTool is what a click in the editor does
+type editorTool int
+
+const (
+	toolPaint editorTool = iota
+	toolErase
+	toolFill
+	toolRectangle
+	toolEntity
+)
+
+var editorToolNames = []string{"paint", "erase", "fill", "rectangle", "entity"}
+
+// editorEntities are the kinds of object the entity tool places. Triggers and
+// exits are dragged out as areas, the rest are points
+var editorEntities = []string{"player", "enemy", "pickup", "checkpoint", "trigger", "exit"}
+
+const (
+	paletteCell    = 24
+	paletteColumns = 9
+	paletteMargin  = 8
+	editorPanSpeed = 600 // pixels per second at zoom 1
+)
+
+// EditorScene edits the Tiled map of the level being played, pushed over
+// gameplay with the Editor action. Leaving hands the edited map back to be played from
+// the start, saving writes it back to the level's file
+type EditorScene struct {
+	Path          string
+	manager       *game_manager.SceneManager
+	document      *game_manager.TiledDocument
+	tileMap       *game_manager.TileMap
+	palette       []game_manager.PaletteTile
+	characters    []string
+	camera        rl.Camera2D
+	tool          editorTool
+	selected      int // palette index of the tile being painted
+	layer         int // tile layer being edited
+	entity        int
+	archetype     int
+	showGrid      bool
+	showCollision bool
+	dragging      bool
+	dragButton    int32
+	dragStart     rl.Vector2 // world position the drag started at
+	dirty         bool
+	status        string
+	keys          map[int32]bool
+	buttons       map[int32]bool
+	onPlay        func(tileMap *game_manager.TileMap)
+}
+
+// NewEditorScene creates an editor for the map at path looking at focus. onPlay
+// is given the edited map, which it then owns, when the designer goes back to playing
+func NewEditorScene(path string, focus rl.Vector2, onPlay func(tileMap *game_manager.TileMap)) *EditorScene {
+	return &EditorScene{
+		Path:     path,
+		camera:   rl.NewCamera2D(rl.Vector2{}, focus, 0, 1),
+		showGrid: true,
+		keys:     map[int32]bool{},
+		buttons:  map[int32]bool{},
+		onPlay:   onPlay,
+	}
+}
+
+func (e *EditorScene) Enter(manager *game_manager.SceneManager) {
+	e.manager = manager
+	e.camera.Offset = rl.Vector2{X: float32(rl.GetScreenWidth()) / 2, Y: float32(rl.GetScreenHeight()) / 2}
+
+	document, err := game_manager.OpenTiledMap(e.Path)
+	if err != nil {
+		log.Printf("editor: %v", err)
+		manager.Pop()
+		return
+	}
+	e.document = document
+	e.palette = document.Palette()
+	e.layer = max(document.CollisionLayer(), 0)
+	e.characters = characters.Characters()
+	e.rebuild()
+}
+
+func (e *EditorScene) Exit() {
+	if e.tileMap != nil {
+		e.tileMap.Unload()
+	}
+	if e.document != nil {
+		e.document.Close()
+	}
+}
+
+// Update applies the editor's keyboard and mouse controls
+func (e *EditorScene) Update(dt float32) {
+	if e.document == nil {
+		return
+	}
+
+	if input.Default.Pressed(input.Editor) {
+		e.play()
+		return
+	}
+
+	e.updateShortcuts()
+	e.updateCamera(dt)
+	e.updateMouse()
+
+	if e.dirty {
+		e.rebuild()
+	}
+
+	e.remember()
+}
+
+// play hands a fresh build of the map to gameplay and closes the editor
+func (e *EditorScene) play() {
+	tileMap, err := e.document.Build()
+	if err != nil {
+		e.status = err.Error()
+		return
+	}
+	e.onPlay(tileMap)
+	e.manager.Pop()
+}
+
+// updateShortcuts handles the tool, layer, overlay, history and file keys
+func (e *EditorScene) updateShortcuts() {
+	ctrl := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	shift := rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift)
+
+	for i := range editorToolNames {
+		if e.keyPressed(rl.KeyOne + int32(i)) {
+			e.tool = editorTool(i)
+		}
+	}
+
+	// Every key is checked each tick, so a press is never missed behind a short circuit
+	save, undo, redo := e.keyPressed(rl.KeyS), e.keyPressed(rl.KeyZ), e.keyPressed(rl.KeyY)
+	switch {
+	case ctrl && save:
+		if err := e.document.Save(); err != nil {
+			e.status = err.Error()
+		} else {
+			e.status = "saved " + e.Path
+		}
+	case ctrl && (redo || (shift && undo)):
+		e.dirty = e.document.Redo() || e.dirty
+	case ctrl && undo:
+		e.dirty = e.document.Undo() || e.dirty
+	}
+
+	if e.keyPressed(rl.KeyTab) {
+		e.entity = (e.entity + 1) % len(editorEntities)
+	}
+	if len(e.characters) > 0 {
+		if e.keyPressed(rl.KeyRightBracket) {
+			e.archetype = (e.archetype + 1) % len(e.characters)
+		}
+		if e.keyPressed(rl.KeyLeftBracket) {
+			e.archetype = (e.archetype + len(e.characters) - 1) % len(e.characters)
+		}
+	}
+	if layers := len(e.document.TileLayers()); layers > 0 && e.keyPressed(rl.KeyL) {
+		e.layer = (e.layer + 1) % layers
+	}
+	if e.keyPressed(rl.KeyG) {
+		e.showGrid = !e.showGrid
+	}
+	if e.keyPressed(rl.KeyC) {
+		e.showCollision = !e.showCollision
+	}
+	if e.keyPressed(rl.KeyT) {
+		e.document.Autotile = !e.document.Autotile
+	}
+
+	// Shift and the arrow keys grow or shrink the map a tile at a time
+	right, left, down, up := e.keyPressed(rl.KeyRight), e.keyPressed(rl.KeyLeft), e.keyPressed(rl.KeyDown), e.keyPressed(rl.KeyUp)
+	if shift {
+		width, height := e.document.Width(), e.document.Height()
+		switch {
+		case right:
+			width++
+		case left:
+			width--
+		case down:
+			height++
+		case up:
+			height--
+		}
+		if width != e.document.Width() || height != e.document.Height() {
+			e.document.Checkpoint()
+			e.dirty = e.document.Resize(width, height) || e.dirty
+		}
+	}
+}
+
+// updateCamera pans with the arrow keys
+func (e *EditorScene) updateCamera(dt float32) {
+	if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+		return
+	}
+
+	pan := editorPanSpeed * dt / e.camera.Zoom
+	if rl.IsKeyDown(rl.KeyRight) {
+		e.camera.Target.X += pan
+	}
+	if rl.IsKeyDown(rl.KeyLeft) {
+		e.camera.Target.X -= pan
+	}
+	if rl.IsKeyDown(rl.KeyDown) {
+		e.camera.Target.Y += pan
+	}
+	if rl.IsKeyDown(rl.KeyUp) {
+		e.camera.Target.Y -= pan
+	}
+}
+
+// updateView pans with the middle mouse button and zooms with the wheel. The
+// mouse reports movement per frame, so this runs from Draw rather than every tick
+func (e *EditorScene) updateView() {
+	if rl.IsMouseButtonDown(rl.MouseButtonMiddle) {
+		delta := rl.GetMouseDelta()
+		e.camera.Target.X -= delta.X / e.camera.Zoom
+		e.camera.Target.Y -= delta.Y / e.camera.Zoom
+	}
+
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 && !e.overPalette(rl.GetMousePosition()) {
+		e.camera.Zoom = min(max(e.camera.Zoom*float32(math.Pow(1.1, float64(wheel))), 0.25), 4)
+	}
+}
+
+// updateMouse runs the current tool, a drag is one undo step however many tiles it touches
+func (e *EditorScene) updateMouse() {
+	mouse := rl.GetMousePosition()
+	world := rl.GetScreenToWorld2D(mouse, e.camera)
+
+	for _, button := range []int32{rl.MouseButtonLeft, rl.MouseButtonRight} {
+		if !e.buttonPressed(button) || e.dragging {
+			continue
+		}
+		if e.overPalette(mouse) {
+			e.pickPalette(mouse)
+			continue
+		}
+
+		e.dragging = true
+		e.dragButton = button
+		e.dragStart = world
+		e.document.Checkpoint()
+		e.press(button, world)
+	}
+
+	if !e.dragging {
+		return
+	}
+
+	if rl.IsMouseButtonDown(e.dragButton) {
+		if e.tool == toolPaint || e.tool == toolErase {
+			e.paint(e.dragButton, world)
+		}
+		return
+	}
+
+	// The drag just ended
+	e.dragging = false
+	e.release(e.dragButton, world)
+}
+
+// press starts a tool's action where the mouse went down
+func (e *EditorScene) press(button int32, world rl.Vector2) {
+	x, y := e.cell(world)
+
+	switch e.tool {
+	case toolPaint, toolErase:
+		e.paint(button, world)
+	case toolFill:
+		e.dirty = e.document.Fill(e.layer, x, y, e.brush(button)) || e.dirty
+	case toolEntity:
+		if button == rl.MouseButtonRight {
+			e.dirty = e.document.RemoveObjectAt(world) || e.dirty
+		} else if !e.entityIsArea() {
+			e.placeEntity(rl.NewRectangle(world.X, world.Y, 0, 0))
+		}
+	}
+}
+
+// release finishes the tools that act on the whole dragged area
+func (e *EditorScene) release(button int32, world rl.Vector2) {
+	switch e.tool {
+	case toolRectangle:
+		x0, y0 := e.cell(e.dragStart)
+		x1, y1 := e.cell(world)
+		e.dirty = e.document.FillRect(e.layer, x0, y0, x1, y1, e.brush(button)) || e.dirty
+	case toolEntity:
+		if button == rl.MouseButtonLeft && e.entityIsArea() {
+			area := e.dragArea(world)
+			if area.Width > 0 && area.Height > 0 {
+				e.placeEntity(area)
+			}
+		}
+	}
+}
+
+func (e *EditorScene) paint(button int32, world rl.Vector2) {
+	x, y := e.cell(world)
+	e.dirty = e.document.SetTile(e.layer, x, y, e.brush(button)) || e.dirty
+}
+
+// brush returns the tile a mouse button lays down, the right button and the erase tool clear
+func (e *EditorScene) brush(button int32) uint32 {
+	if button == rl.MouseButtonRight || e.tool == toolErase || len(e.palette) == 0 {
+		return 0
+	}
+	return e.palette[e.selected].GID
+}
+
+// placeEntity adds an object of the selected kind covering area, points have no size
+func (e *EditorScene) placeEntity(area rl.Rectangle) {
+	kind := editorEntities[e.entity]
+	object := game_manager.MapObject{
+		Name:       kind,
+		Class:      kind,
+		X:          area.X,
+		Y:          area.Y,
+		Width:      area.Width,
+		Height:     area.Height,
+		Point:      area.Width == 0 && area.Height == 0,
+		Properties: game_manager.Properties{},
+	}
+
+	switch kind {
+	case "enemy":
+		if len(e.characters) > 0 {
+			object.Name = e.characters[e.archetype]
+		}
+		object.Properties["health"] = "3"
+	case "pickup":
+		object.Name = "health"
+		object.Properties["amount"] = "1"
+	}
+
+	e.document.AddObject(object)
+	e.dirty = true
+}
+
+func (e *EditorScene) entityIsArea() bool {
+	kind := editorEntities[e.entity]
+	return kind == "trigger" || kind == "exit"
+}
+
+// dragArea returns the grid cells between where the drag started and world
+func (e *EditorScene) dragArea(world rl.Vector2) rl.Rectangle {
+	tileWidth, tileHeight := e.document.TileSize()
+	x0, y0 := e.cell(e.dragStart)
+	x1, y1 := e.cell(world)
+	return rl.NewRectangle(
+		float32(min(x0, x1)*tileWidth),
+		float32(min(y0, y1)*tileHeight),
+		float32((max(x0, x1)-min(x0, x1)+1)*tileWidth),
+		float32((max(y0, y1)-min(y0, y1)+1)*tileHeight),
+	)
+}
+
+// cell returns the grid cell under a world position
+func (e *EditorScene) cell(world rl.Vector2) (int, int) {
+	tileWidth, tileHeight := e.document.TileSize()
+	return int(math.Floor(float64(world.X) / float64(tileWidth))), int(math.Floor(float64(world.Y) / float64(tileHeight)))
+}
+
+// rebuild replaces the map being drawn with a build of the document as it is now
+func (e *EditorScene) rebuild() {
+	e.dirty = false
+
+	tileMap, err := e.document.Build()
+	if err != nil {
+		e.status = err.Error()
+		return
+	}
+	if e.tileMap != nil {
+		e.tileMap.Unload()
+	}
+	e.tileMap = tileMap
+}
+
+// paletteBounds returns the screen area of the tile palette
+func (e *EditorScene) paletteBounds() rl.Rectangle {
+	rows := (len(e.palette) + paletteColumns - 1) / paletteColumns
+	width := float32(paletteColumns*paletteCell + 2*paletteMargin)
+	return rl.NewRectangle(float32(rl.GetScreenWidth())-width, 40, width, float32(rows*paletteCell+2*paletteMargin))
+}
+
+func (e *EditorScene) overPalette(mouse rl.Vector2) bool {
+	return e.tool != toolEntity && len(e.palette) > 0 && rl.CheckCollisionPointRec(mouse, e.paletteBounds())
+}
+
+func (e *EditorScene) pickPalette(mouse rl.Vector2) {
+	bounds := e.paletteBounds()
+	column := int(mouse.X-bounds.X-paletteMargin) / paletteCell
+	row := int(mouse.Y-bounds.Y-paletteMargin) / paletteCell
+	if index := row*paletteColumns + column; column >= 0 && column < paletteColumns && index >= 0 && index < len(e.palette) {
+		e.selected = index
+	}
+}
+
+// keyPressed reports whether a key went down since the last tick. The editor
+// tracks this itself as the fixed timestep can run several ticks in one frame
+func (e *EditorScene) keyPressed(key int32) bool {
+	if _, ok := e.keys[key]; !ok {
+		e.keys[key] = rl.IsKeyDown(key)
+		return false
+	}
+	return rl.IsKeyDown(key) && !e.keys[key]
+}
+
+// buttonPressed reports whether a mouse button went down since the last tick
+func (e *EditorScene) buttonPressed(button int32) bool {
+	if _, ok := e.buttons[button]; !ok {
+		e.buttons[button] = rl.IsMouseButtonDown(button)
+		return false
+	}
+	return rl.IsMouseButtonDown(button) && !e.buttons[button]
+}
+
+// remember records which of the keys and buttons asked about are down, for the next tick
+func (e *EditorScene) remember() {
+	for key := range e.keys {
+		e.keys[key] = rl.IsKeyDown(key)
+	}
+	for button := range e.buttons {
+		e.buttons[button] = rl.IsMouseButtonDown(button)
+	}
+}
+
+// Draw renders the map, its objects and overlays with the palette and status bar on top
+func (e *EditorScene) Draw(alpha float32) {
+	if e.tileMap == nil {
+		return
+	}
+
+	e.updateView()
+
+	rl.ClearBackground(rl.NewColor(40, 44, 52, 255))
+
+	view := game_manager.CameraView(e.camera)
+	rl.BeginMode2D(e.camera)
+	e.tileMap.DrawBackground(view)
+	e.tileMap.DrawForeground(view)
+	e.drawMapOverlays()
+	e.drawObjects()
+	e.drawCursor()
+	rl.EndMode2D()
+
+	e.drawPalette()
+	e.drawStatus()
+}
+
+// drawMapOverlays outlines the map and draws the grid and collision shapes when they are on
+func (e *EditorScene) drawMapOverlays() {
+	tileWidth, tileHeight := e.document.TileSize()
+	width := float32(e.document.Width() * tileWidth)
+	height := float32(e.document.Height() * tileHeight)
+
+	if e.showGrid {
+		lineColour := rl.Fade(rl.White, 0.15)
+		for x := 0; x <= e.document.Width(); x++ {
+			rl.DrawLineV(rl.Vector2{X: float32(x * tileWidth)}, rl.Vector2{X: float32(x * tileWidth), Y: height}, lineColour)
+		}
+		for y := 0; y <= e.document.Height(); y++ {
+			rl.DrawLineV(rl.Vector2{Y: float32(y * tileHeight)}, rl.Vector2{X: width, Y: float32(y * tileHeight)}, lineColour)
+		}
+	}
+
+	if e.showCollision {
+		e.tileMap.Tiles.ForEach(func(x, y int, tile *game_manager.Tile) {
+			rl.DrawRectangleRec(e.tileMap.Tiles.CellRect(x, y), collisionColour(tile.Definition))
+		})
+	}
+
+	rl.DrawRectangleLinesEx(rl.NewRectangle(0, 0, width, height), 2, rl.White)
+}
+
+// collisionColour shows how characters collide with a tile
+func collisionColour(definition game_manager.TileDefinition) rl.Color {
+	switch {
+	case definition.Damage > 0:
+		return rl.Fade(rl.Purple, 0.5)
+	case definition.Shape == game_manager.ShapeNone:
+		return rl.Blank
+	case definition.Shape == game_manager.ShapeOneWay:
+		return rl.Fade(rl.Yellow, 0.4)
+	case definition.Shape.IsSlope():
+		return rl.Fade(rl.Orange, 0.4)
+	case definition.Friction < 1:
+		return rl.Fade(rl.SkyBlue, 0.4)
+	}
+	return rl.Fade(rl.Red, 0.3)
+}
+
+// drawObjects marks every spawn, checkpoint, exit and trigger in the map
+func (e *EditorScene) drawObjects() {
+	for _, layer := range e.tileMap.ObjectLayers {
+		for _, object := range layer.Objects {
+			colour := rl.LightGray
+			switch object.Class {
+			case "player":
+				colour = rl.Green
+			case "enemy":
+				colour = rl.Red
+			case "pickup":
+				colour = rl.Pink
+			case "checkpoint":
+				colour = rl.SkyBlue
+			case "trigger":
+				colour = rl.Purple
+			case "exit":
+				colour = rl.Gold
+			}
+
+			label := object.Class
+			if object.Name != "" && object.Name != object.Class {
+				label += " " + object.Name
+			}
+
+			if object.Point || object.Width == 0 || object.Height == 0 {
+				rl.DrawCircleV(rl.Vector2{X: object.X, Y: object.Y}, 6, colour)
+				rl.DrawText(label, int32(object.X)+8, int32(object.Y)-6, 10, colour)
+			} else if object.Class != "" {
+				rl.DrawRectangleLinesEx(object.Bounds(), 2, colour)
+				rl.DrawText(label, int32(object.X)+4, int32(object.Y)+4, 10, colour)
+			}
+		}
+	}
+}
+
+// drawCursor highlights the cell under the mouse, or the area being dragged out
+func (e *EditorScene) drawCursor() {
+	mouse := rl.GetMousePosition()
+	if e.overPalette(mouse) {
+		return
+	}
+	world := rl.GetScreenToWorld2D(mouse, e.camera)
+
+	if e.dragging && (e.tool == toolRectangle || (e.tool == toolEntity && e.entityIsArea())) {
+		rl.DrawRectangleLinesEx(e.dragArea(world), 2, rl.White)
+		return
+	}
+	if e.tool == toolEntity {
+		return
+	}
+
+	tileWidth, tileHeight := e.document.TileSize()
+	x, y := e.cell(world)
+	cell := rl.NewRectangle(float32(x*tileWidth), float32(y*tileHeight), float32(tileWidth), float32(tileHeight))
+	if e.tool == toolPaint && len(e.palette) > 0 {
+		tile := e.palette[e.selected]
+		rl.DrawTexturePro(tile.Texture, tile.Source, cell, rl.Vector2{}, 0, rl.Fade(rl.White, 0.6))
+	}
+	rl.DrawRectangleLinesEx(cell, 1, rl.White)
+}
+
+// drawPalette shows the tiles that can be painted with the selected one outlined
+func (e *EditorScene) drawPalette() {
+	if e.tool == toolEntity || len(e.palette) == 0 {
+		return
+	}
+
+	bounds := e.paletteBounds()
+	rl.DrawRectangleRec(bounds, rl.Fade(rl.Black, 0.7))
+	for i, tile := range e.palette {
+		cell := rl.NewRectangle(
+			bounds.X+paletteMargin+float32(i%paletteColumns*paletteCell),
+			bounds.Y+paletteMargin+float32(i/paletteColumns*paletteCell),
+			paletteCell,
+			paletteCell,
+		)
+		rl.DrawTexturePro(tile.Texture, tile.Source, cell, rl.Vector2{}, 0, rl.White)
+		if i == e.selected {
+			rl.DrawRectangleLinesEx(cell, 2, rl.Yellow)
+		}
+	}
+}
+
+// drawStatus shows the tool, layer and map size along with the controls
+func (e *EditorScene) drawStatus() {
+	screenWidth := int32(rl.GetScreenWidth())
+	screenHeight := int32(rl.GetScreenHeight())
+
+	layer := ""
+	if layers := e.document.TileLayers(); e.layer < len(layers) {
+		layer = layers[e.layer]
+	}
+
+	status := fmt.Sprintf("EDITOR %s  tool %s  layer %s  %dx%d", e.Path, editorToolNames[e.tool], layer, e.document.Width(), e.document.Height())
+	if e.tool == toolEntity {
+		status += "  place " + editorEntities[e.entity]
+		if editorEntities[e.entity] == "enemy" && len(e.characters) > 0 {
+			status += " " + e.characters[e.archetype]
+		}
+	}
+	if !e.document.Autotile {
+		status += "  autotile off"
+	}
+	if e.document.Modified {
+		status += "  *"
+	}
+
+	rl.DrawRectangle(0, 0, screenWidth, 34, rl.Fade(rl.Black, 0.7))
+	rl.DrawText(status, 10, 8, 20, rl.RayWhite)
+
+	help := "1-5 tools  tab entity  [ ] enemy  l layer  g grid  c collision  t autotile  shift+arrows resize  ctrl+z/y undo/redo  ctrl+s save  f1 play"
+	rl.DrawRectangle(0, screenHeight-28, screenWidth, 28, rl.Fade(rl.Black, 0.7))
+	rl.DrawText(help, 10, screenHeight-22, 10, rl.LightGray)
+	if e.status != "" {
+		rl.DrawText(e.status, 10, screenHeight-48, 10, rl.Yellow)
+	}
+}
@@ -0,0 +1,40 @@
+package scenes
+
+import (
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// GameOverScene is shown when the player dies, returning to the title screen
+type GameOverScene struct {
+	manager *game_manager.SceneManager
+}
+
+// NewGameOverScene creates the game over screen
+func NewGameOverScene() *GameOverScene {
+	return &GameOverScene{}
+}
+
+func (g *GameOverScene) Enter(manager *game_manager.SceneManager) {
+	g.manager = manager
+}
+
+func (g *GameOverScene) Exit() {}
+
+// Update returns to the title screen once confirmed
+func (g *GameOverScene) Update(dt float32) {
+	if input.Default.Pressed(input.Confirm) {
+		g.manager.Replace(NewTitleScene())
+	}
+}
+
+// Draw shows the game over text
+func (g *GameOverScene) Draw(alpha float32) {
+	screenHeight := int32(rl.GetScreenHeight())
+
+	rl.ClearBackground(rl.Black)
+	drawCenteredText("GAME OVER", screenHeight/2-40, 40, rl.Red)
+	drawCenteredText("press enter to continue", screenHeight/2+10, 20, rl.Gray)
+}
@@ -0,0 +1,228 @@
+package scenes
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/replay"
+	"github.com/grcatterall/go-game/classes/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RecordPath is where gameplay input is recorded to for replaying later,
+// recording is off when empty. Each level is a recording of its own, saved
+// with the level number before the extension, see LevelRecordPath
+var RecordPath string
+
+// BakeTiles draws the level's tiles into a texture per chunk ahead of drawing them
+var BakeTiles bool
+
+// ShowDebug outlines exits, triggers, platforms, checkpoints and hitboxes, the
+// Debug action toggles it in play
+var ShowDebug bool
+
+// Endless is set when the levels are generated rather than read from the
+// registry, EndlessSeed being the seed they are generated from. Recordings
+// keep both so endless runs can be replayed
+var (
+	Endless     bool
+	EndlessSeed int64
+)
+
+// TickRate is the simulation rate the game loop steps scenes at
+var TickRate float32 = 60
+
+// GameplayScene runs a single level, drawing the world with a follow camera and parallax background
+type GameplayScene struct {
+	Level              int
+	manager            *game_manager.SceneManager
+	world              *world.World
+	recorder           *replay.Recorder
+	parallaxBackground *game_manager.ParallaxBackground
+	camera             rl.Camera2D
+	music              rl.Music
+	hasMusic           bool
+}
+
+// NewGameplayScene creates a gameplay scene for the given level
+func NewGameplayScene(level int) *GameplayScene {
+	return &GameplayScene{Level: level}
+}
+
+// Enter loads the level, its characters and background
+func (g *GameplayScene) Enter(manager *game_manager.SceneManager) {
+	g.manager = manager
+
+	g.world = world.NewWorld(g.Level, time.Now().UnixNano())
+	g.world.Debug = ShowDebug
+
+	if RecordPath != "" {
+		g.recorder = replay.NewRecorder(g.world, TickRate)
+		g.recorder.Recording.Endless = Endless
+		g.recorder.Recording.EndlessSeed = EndlessSeed
+	}
+
+	settings := g.world.Settings
+	g.parallaxBackground = newBackground(settings.Background)
+
+	if settings.Music != "" {
+		g.music = rl.LoadMusicStream(settings.Music)
+		g.hasMusic = rl.IsMusicReady(g.music)
+		if g.hasMusic {
+			rl.PlayMusicStream(g.music)
+		} else {
+			log.Printf("could not load level music %s", settings.Music)
+		}
+	}
+
+	g.camera = rl.NewCamera2D(g.world.Player.Position, rl.Vector2{X: 0, Y: 0}, 0, 1)
+}
+
+// Exit releases everything the level loaded
+func (g *GameplayScene) Exit() {
+	if g.recorder != nil {
+		path := LevelRecordPath(RecordPath, g.Level)
+		if err := g.recorder.Recording.Save(path); err != nil {
+			log.Printf("could not save replay: %v", err)
+		}
+	}
+
+	g.world.Unload()
+	g.parallaxBackground.Unload()
+
+	if g.hasMusic {
+		rl.UnloadMusicStream(g.music)
+	}
+}
+
+// Update steps the level by dt seconds
+func (g *GameplayScene) Update(dt float32) {
+	if input.Default.Pressed(input.Pause) {
+		g.manager.Push(NewPauseScene())
+		return
+	}
+
+	if input.Default.Pressed(input.Debug) {
+		ShowDebug = !ShowDebug
+		g.world.Debug = ShowDebug
+	}
+
+	if input.Default.Pressed(input.Editor) {
+		g.openEditor()
+		return
+	}
+
+	g.world.Step(dt)
+
+	if g.recorder != nil {
+		g.recorder.Record(g.world, input.Default.State())
+	}
+
+	if name, ok := g.world.NextCutscene(); ok {
+		g.manager.Push(NewCutsceneScene(g.world.CutsceneLines(name)))
+		return
+	}
+
+	// Dying or falling out of the bottom of the map ends the run, the world
+	// respawns the player itself once they have touched a checkpoint
+	if g.world.PlayerDead() {
+		g.manager.Replace(NewGameOverScene())
+		return
+	}
+
+	if g.world.LevelComplete() {
+		g.manager.Replace(NewLevelCompleteScene(g.Level))
+	}
+}
+
+// LevelRecordPath returns where a level's recording is saved, run.rec becoming
+// run.level2.rec for level 2, so playing on doesn't overwrite earlier levels
+func LevelRecordPath(path string, level int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.level%d%s", strings.TrimSuffix(path, ext), level, ext)
+}
+
+// openEditor pushes the level editor over the level, recorded runs can't be
+// edited as the recording would no longer match the level
+func (g *GameplayScene) openEditor() {
+	path := g.world.Settings.MapPath()
+	if path == "" || g.recorder != nil {
+		log.Printf("level %d can't be edited: it has no Tiled map or is being recorded", g.Level)
+		return
+	}
+	g.manager.Push(NewEditorScene(path, g.world.Player.Position, g.playEdited))
+}
+
+// playEdited restarts the level on the map handed back by the editor
+func (g *GameplayScene) playEdited(tileMap *game_manager.TileMap) {
+	g.world.Unload()
+	g.world = world.NewWorldFromMap(g.Level, tileMap, time.Now().UnixNano())
+	g.world.Debug = ShowDebug
+}
+
+// Draw renders the level following the player
+func (g *GameplayScene) Draw(alpha float32) {
+	// Music streams need feeding every frame, including while paused under another scene
+	if g.hasMusic {
+		rl.UpdateMusicStream(g.music)
+	}
+
+	g.camera.Target = g.world.Player.RenderPosition(alpha)
+	view := game_manager.CameraView(g.camera)
+	if BakeTiles {
+		g.world.TileMap.Bake(view)
+	}
+
+	rl.BeginMode2D(g.camera)
+
+	g.parallaxBackground.Update(g.camera.Target.X)
+	g.parallaxBackground.Draw()
+	if input.Default.GamepadConnected() {
+		rl.DrawText("left stick move (push to sprint) - a jump - right trigger shoot - x melee - start pause", 100, 30, 20, rl.Black)
+	} else {
+		rl.DrawText("< a d > - shift sprint - left click shoot - right click melee - p pause - f1 edit - f3 debug", 100, 30, 20, rl.Black)
+	}
+
+	g.world.Draw(alpha, view)
+
+	rl.EndMode2D()
+
+	rl.DrawText(fmt.Sprintf("HEALTH %d", max(g.world.Player.Health, 0)), 20, 60, 20, rl.Maroon)
+	drawMessage(g.world)
+}
+
+// drawMessage shows the message a trigger put on screen, if there is one
+func drawMessage(w *world.World) {
+	if w.Message != "" {
+		drawCenteredText(w.Message, int32(rl.GetScreenHeight())/4, 30, rl.White)
+	}
+}
+
+// newBackground loads the parallax background layers of a background set,
+// Day or Night, in assets/world/2 Background
+func newBackground(set string) *game_manager.ParallaxBackground {
+	if set == "" {
+		set = "Day"
+	}
+
+	layerFiles := []string{
+		"assets/world/2 Background/" + set + "/1.png",
+		"assets/world/2 Background/" + set + "/2.png",
+		"assets/world/2 Background/" + set + "/3.png",
+		"assets/world/2 Background/" + set + "/4.png",
+	}
+	speeds := []float32{
+		1.0,
+		0.2,
+		0.4,
+		0.8,
+	}
+
+	return game_manager.NewParallaxBackground(layerFiles, speeds)
+}
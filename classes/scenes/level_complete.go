@@ -0,0 +1,55 @@
+package scenes
+
+import (
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// LevelCompleteScene is shown when the player reaches a level's exit, moving on
+// to the next level or back to the title screen after the last one
+type LevelCompleteScene struct {
+	Level   int
+	next    *levels.Level
+	manager *game_manager.SceneManager
+}
+
+// NewLevelCompleteScene creates the screen shown after finishing the given level
+func NewLevelCompleteScene(level int) *LevelCompleteScene {
+	return &LevelCompleteScene{Level: level}
+}
+
+func (l *LevelCompleteScene) Enter(manager *game_manager.SceneManager) {
+	l.manager = manager
+	l.next = levels.Default.Next(l.Level)
+}
+
+func (l *LevelCompleteScene) Exit() {}
+
+// Update moves on once confirmed
+func (l *LevelCompleteScene) Update(dt float32) {
+	if !input.Default.Pressed(input.Confirm) {
+		return
+	}
+
+	if l.next != nil {
+		l.manager.Replace(NewLevelTransitionScene(l.next.Number))
+	} else {
+		l.manager.Replace(NewTitleScene())
+	}
+}
+
+// Draw shows the level complete text
+func (l *LevelCompleteScene) Draw(alpha float32) {
+	screenHeight := int32(rl.GetScreenHeight())
+
+	rl.ClearBackground(rl.Black)
+	if l.next != nil {
+		drawCenteredText("LEVEL COMPLETE", screenHeight/2-40, 40, rl.Gold)
+	} else {
+		drawCenteredText("YOU BEAT EVERY LEVEL", screenHeight/2-40, 40, rl.Gold)
+	}
+	drawCenteredText("press enter to continue", screenHeight/2+10, 20, rl.Gray)
+}
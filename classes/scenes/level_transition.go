@@ -0,0 +1,52 @@
+package scenes
+
+import (
+	"fmt"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// LevelTransitionScene shows the level number for a moment before starting it
+type LevelTransitionScene struct {
+	Level    int
+	Duration float32
+	elapsed  float32
+	manager  *game_manager.SceneManager
+}
+
+// NewLevelTransitionScene creates a transition card leading into the given level
+func NewLevelTransitionScene(level int) *LevelTransitionScene {
+	return &LevelTransitionScene{
+		Level:    level,
+		Duration: 2,
+	}
+}
+
+func (l *LevelTransitionScene) Enter(manager *game_manager.SceneManager) {
+	l.manager = manager
+	l.elapsed = 0
+}
+
+func (l *LevelTransitionScene) Exit() {}
+
+// Update starts the level once the card has been shown for long enough
+func (l *LevelTransitionScene) Update(dt float32) {
+	l.elapsed += dt
+	if l.elapsed >= l.Duration {
+		l.manager.Replace(NewGameplayScene(l.Level))
+	}
+}
+
+// Draw shows the upcoming level number and name
+func (l *LevelTransitionScene) Draw(alpha float32) {
+	screenHeight := int32(rl.GetScreenHeight())
+
+	rl.ClearBackground(rl.Black)
+	drawCenteredText(fmt.Sprintf("LEVEL %d", l.Level), screenHeight/2-20, 40, rl.RayWhite)
+	if settings := levels.Default.Level(l.Level); settings != nil {
+		drawCenteredText(settings.Name, screenHeight/2+30, 20, rl.Gray)
+	}
+}
@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PauseScene is an overlay pushed over gameplay, freezing it until resumed
+type PauseScene struct {
+	manager *game_manager.SceneManager
+}
+
+// NewPauseScene creates a pause overlay
+func NewPauseScene() *PauseScene {
+	return &PauseScene{}
+}
+
+func (p *PauseScene) Enter(manager *game_manager.SceneManager) {
+	p.manager = manager
+}
+
+func (p *PauseScene) Exit() {}
+
+// Update resumes or quits to the title screen
+func (p *PauseScene) Update(dt float32) {
+	if input.Default.Pressed(input.Pause) {
+		p.manager.Pop()
+	}
+
+	if input.Default.Pressed(input.Back) {
+		// Remove ourselves and the gameplay scene underneath
+		p.manager.Pop()
+		p.manager.Replace(NewTitleScene())
+	}
+}
+
+// Draw dims whatever is underneath and shows the pause text
+func (p *PauseScene) Draw(alpha float32) {
+	screenWidth := int32(rl.GetScreenWidth())
+	screenHeight := int32(rl.GetScreenHeight())
+
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.Fade(rl.Black, 0.5))
+	drawCenteredText("PAUSED", screenHeight/2-40, 40, rl.White)
+	drawCenteredText("p - resume    q - quit to title", screenHeight/2+10, 20, rl.White)
+}
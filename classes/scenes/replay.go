@@ -0,0 +1,118 @@
+package scenes
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/replay"
+	"github.com/grcatterall/go-game/classes/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// seekStep is how many seconds the seek keys jump
+const seekStep = 5
+
+// ReplayScene plays back a recorded run with pause, speed and seek controls
+type ReplayScene struct {
+	recording          *replay.Recording
+	playback           *replay.Playback
+	manager            *game_manager.SceneManager
+	parallaxBackground *game_manager.ParallaxBackground
+	camera             rl.Camera2D
+}
+
+// NewReplayScene creates a scene playing back the given recording
+func NewReplayScene(recording *replay.Recording) *ReplayScene {
+	return &ReplayScene{recording: recording}
+}
+
+func (r *ReplayScene) Enter(manager *game_manager.SceneManager) {
+	r.manager = manager
+
+	r.playback = replay.NewPlayback(r.recording, world.NewWorld)
+	r.playback.OnDesync = func(desync replay.Desync) {
+		log.Printf("replay desync at tick %d: expected %016x, got %016x", desync.Tick, desync.Expected, desync.Actual)
+	}
+
+	r.parallaxBackground = newBackground(r.playback.World.Settings.Background)
+	r.camera = rl.NewCamera2D(r.playback.World.Player.Position, rl.Vector2{X: 0, Y: 0}, 0, 1)
+}
+
+func (r *ReplayScene) Exit() {
+	r.playback.World.Unload()
+	r.parallaxBackground.Unload()
+}
+
+// Update handles the playback controls and advances the replay
+func (r *ReplayScene) Update(dt float32) {
+	if input.Default.Pressed(input.Back) {
+		r.manager.Replace(NewTitleScene())
+		return
+	}
+
+	if input.Default.Pressed(input.Pause) {
+		r.playback.Paused = !r.playback.Paused
+	}
+
+	if input.Default.Pressed(input.ReplayFaster) && r.playback.Speed < 8 {
+		r.playback.Speed *= 2
+	}
+	if input.Default.Pressed(input.ReplaySlower) && r.playback.Speed > 0.125 {
+		r.playback.Speed /= 2
+	}
+
+	seekTicks := uint64(seekStep * r.recording.TickRate)
+	if input.Default.Pressed(input.SeekForward) {
+		r.playback.Seek(r.playback.Tick() + seekTicks)
+	}
+	if input.Default.Pressed(input.SeekBack) {
+		if r.playback.Tick() > seekTicks {
+			r.playback.Seek(r.playback.Tick() - seekTicks)
+		} else {
+			r.playback.Seek(0)
+		}
+	}
+
+	r.playback.Update()
+
+	// Replays don't stop for cutscenes
+	for {
+		if _, ok := r.playback.World.NextCutscene(); !ok {
+			break
+		}
+	}
+}
+
+// Draw renders the replayed world and the playback status
+func (r *ReplayScene) Draw(alpha float32) {
+	w := r.playback.World
+	if r.playback.Paused || r.playback.Done() {
+		alpha = 1
+	}
+
+	r.camera.Target = w.Player.RenderPosition(alpha)
+	view := game_manager.CameraView(r.camera)
+	if BakeTiles {
+		w.TileMap.Bake(view)
+	}
+
+	rl.BeginMode2D(r.camera)
+	r.parallaxBackground.Update(r.camera.Target.X)
+	r.parallaxBackground.Draw()
+	w.Draw(alpha, view)
+	rl.EndMode2D()
+
+	status := fmt.Sprintf("REPLAY tick %d/%d  x%.3g", r.playback.Tick(), r.playback.Length(), r.playback.Speed)
+	if r.playback.Paused {
+		status += "  paused"
+	}
+	if len(r.playback.Desyncs) > 0 {
+		status += fmt.Sprintf("  DESYNC at tick %d", r.playback.Desyncs[0].Tick)
+	}
+	rl.DrawText(status, 10, 10, 20, rl.Black)
+	rl.DrawText("p pause - up/down speed - left/right seek - q quit", 10, 34, 20, rl.Black)
+	drawMessage(w)
+}
@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TitleScene is the first screen shown, waiting for the player to start
+type TitleScene struct {
+	manager *game_manager.SceneManager
+}
+
+// NewTitleScene creates the title screen
+func NewTitleScene() *TitleScene {
+	return &TitleScene{}
+}
+
+func (t *TitleScene) Enter(manager *game_manager.SceneManager) {
+	t.manager = manager
+}
+
+func (t *TitleScene) Exit() {}
+
+// Update starts the first level once confirmed
+func (t *TitleScene) Update(dt float32) {
+	if input.Default.Pressed(input.Confirm) {
+		t.manager.Replace(NewLevelTransitionScene(levels.Default.First().Number))
+	}
+}
+
+// Draw shows the game title and start prompt
+func (t *TitleScene) Draw(alpha float32) {
+	screenHeight := int32(rl.GetScreenHeight())
+
+	rl.ClearBackground(rl.Black)
+	drawCenteredText("GO GAME", screenHeight/2-60, 60, rl.RayWhite)
+	drawCenteredText("press enter to start", screenHeight/2+20, 20, rl.Gray)
+}
+
+// drawCenteredText draws text horizontally centred on the screen
+func drawCenteredText(text string, y int32, fontSize int32, color rl.Color) {
+	width := rl.MeasureText(text, fontSize)
+	rl.DrawText(text, (int32(rl.GetScreenWidth())-width)/2, y, fontSize, color)
+}
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"math"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// checkpointMessageTime is how many seconds the message shown on touching a checkpoint stays up
+const checkpointMessageTime = 2
+
+// playerSnapshot is what the player had when they touched a checkpoint, given
+// back when they respawn there. The gun never runs out, so there is no ammo to keep
+type playerSnapshot struct {
+	health     int32
+	facingLeft bool
+}
+
+// touchCheckpoints makes the checkpoint the player is touching the one they
+// respawn at, remembering what they have
+func (w *World) touchCheckpoints() {
+	if w.PlayerDead() {
+		return
+	}
+
+	body := w.Player.Bounds()
+	for _, checkpoint := range w.Checkpoints {
+		if checkpoint == w.Checkpoint || !rl.CheckCollisionRecs(body, checkpoint.Area) {
+			continue
+		}
+		w.Checkpoint = checkpoint
+		w.snapshot = playerSnapshot{health: w.Player.Health, facingLeft: w.Player.IsLeft}
+		w.Message, w.MessageTime = "Checkpoint", checkpointMessageTime
+	}
+}
+
+// respawn brings the player back at the last checkpoint they touched as they
+// were then. Crumbled platforms come back, and the checkpoint's section is put
+// back as the level started when it resets enemies
+func (w *World) respawn() {
+	w.Player.Respawn(w.Checkpoint.Spawn, w.snapshot.health)
+	w.Player.IsLeft = w.snapshot.facingLeft
+
+	for _, platform := range w.Platforms {
+		if platform.Falls {
+			platform.Reset()
+		}
+	}
+
+	if w.Checkpoint.ResetEnemies {
+		w.resetSection(w.Checkpoint)
+	}
+}
+
+// resetSection puts the enemies placed between a checkpoint and the next one
+// along back where they started, dead or not. Waves there wait for their
+// triggers again when a trigger can still spawn them, otherwise their enemies
+// are placed straight away
+func (w *World) resetSection(checkpoint *game_manager.Checkpoint) {
+	from, to := checkpoint.Area.X, float32(math.Inf(1))
+	for _, next := range w.Checkpoints {
+		if next.Area.X > from {
+			to = next.Area.X
+			break
+		}
+	}
+	inSection := func(x float32) bool {
+		return x >= from && x < to
+	}
+
+	rearmed := w.rearmedWaves(inSection)
+
+	alive := w.Enemies[:0]
+	for _, enemy := range w.Enemies {
+		if spawn, ok := w.enemySpawns[enemy]; ok && inSection(spawn.Position.X) {
+			enemy.Unload()
+			delete(w.enemySpawns, enemy)
+			continue
+		}
+		alive = append(alive, enemy)
+	}
+	w.Enemies = alive
+
+	for wave, spawns := range w.waves {
+		var waiting []game_manager.Spawn
+		for _, spawn := range spawns {
+			if !inSection(spawn.Position.X) {
+				waiting = append(waiting, spawn)
+			}
+		}
+		w.waves[wave] = waiting
+	}
+	for _, spawn := range w.enemyPlacements {
+		if !inSection(spawn.Position.X) {
+			continue
+		}
+		if spawn.Wave == "" || !rearmed[spawn.Wave] {
+			w.spawnEnemy(spawn)
+		} else {
+			w.waves[spawn.Wave] = append(w.waves[spawn.Wave], spawn)
+		}
+	}
+
+	for _, trigger := range w.Triggers {
+		if inSection(trigger.Area.X) {
+			trigger.Reset()
+		}
+	}
+}
+
+// rearmedWaves returns the waves a trigger can still spawn once a section is
+// reset: ones still waiting and ones spawned by a trigger that is reset with
+// the section or repeats
+func (w *World) rearmedWaves(inSection func(x float32) bool) map[string]bool {
+	rearmed := map[string]bool{}
+	for wave := range w.waves {
+		rearmed[wave] = true
+	}
+	for _, trigger := range w.Triggers {
+		if !trigger.Repeat && !inSection(trigger.Area.X) {
+			continue
+		}
+		for _, action := range trigger.Actions {
+			if action.Verb == game_manager.ActionSpawn {
+				rearmed[action.Target] = true
+			}
+		}
+	}
+	return rearmed
+}
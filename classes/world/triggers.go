@@ -0,0 +1,163 @@
+package world
+
+import (
+	"log"
+	"strings"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// door is a named area of solid tiles triggers open and close. tiles holds
+// what was in each cell while it's open
+type door struct {
+	area  rl.Rectangle
+	prop  *game_manager.Prop
+	tiles map[[2]int]*game_manager.Tile
+	open  bool
+}
+
+// loadTriggers reads the level's triggers, holds back the enemies of every
+// wave and finds its doors, opening the ones that start open
+func (w *World) loadTriggers(spawns []game_manager.Spawn) {
+	for _, spawn := range game_manager.SpawnsOfKind(spawns, game_manager.SpawnTrigger) {
+		trigger, err := game_manager.NewTrigger(spawn)
+		if err != nil {
+			log.Printf("world: %v", err)
+			continue
+		}
+		w.Triggers = append(w.Triggers, trigger)
+	}
+
+	w.waves = map[string][]game_manager.Spawn{}
+	for _, spawn := range game_manager.SpawnsOfKind(spawns, game_manager.SpawnEnemy) {
+		if spawn.Wave != "" {
+			w.waves[spawn.Wave] = append(w.waves[spawn.Wave], spawn)
+		}
+	}
+
+	w.doors = map[string]*door{}
+	for _, object := range w.TileMap.ObjectsOfClass(game_manager.DoorClass) {
+		d := &door{area: object.Bounds(), prop: w.TileMap.Prop(object.ID)}
+		w.doors[object.Name] = d
+		if object.Properties.Bool("open") {
+			w.openDoor(d)
+		}
+	}
+}
+
+// updateTriggers tells every trigger whether the player is inside it, running
+// the actions of the ones that fire
+func (w *World) updateTriggers(dt float32) {
+	body := w.Player.Bounds()
+	for _, trigger := range w.Triggers {
+		if trigger.Update(rl.CheckCollisionRecs(body, trigger.Area), dt) {
+			w.runActions(trigger)
+		}
+	}
+
+	if w.MessageTime > 0 {
+		w.MessageTime -= dt
+		if w.MessageTime <= 0 {
+			w.Message = ""
+		}
+	}
+}
+
+// runActions carries out what a trigger does, in order
+func (w *World) runActions(trigger *game_manager.Trigger) {
+	for _, action := range trigger.Actions {
+		switch action.Verb {
+		case game_manager.ActionSpawn:
+			w.spawnWave(action.Target)
+		case game_manager.ActionMessage:
+			w.Message, w.MessageTime = action.Text, action.Seconds
+		case game_manager.ActionOpen, game_manager.ActionClose:
+			d, ok := w.doors[action.Target]
+			if !ok {
+				log.Printf("world: trigger %q: no door %q", trigger.Name, action.Target)
+				continue
+			}
+			if action.Verb == game_manager.ActionOpen {
+				w.openDoor(d)
+			} else {
+				w.closeDoor(d)
+			}
+		case game_manager.ActionCutscene:
+			w.cutscenes = append(w.cutscenes, action.Target)
+		case game_manager.ActionEnd:
+			w.ended = true
+		}
+	}
+}
+
+// spawnWave places the enemies of a wave, a wave only ever spawns once
+func (w *World) spawnWave(name string) {
+	for _, spawn := range w.waves[name] {
+		w.spawnEnemy(spawn)
+	}
+	delete(w.waves, name)
+}
+
+// openDoor takes the door's solid tiles out of the map and hides its image
+func (w *World) openDoor(d *door) {
+	if d.open {
+		return
+	}
+	d.open = true
+	d.tiles = map[[2]int]*game_manager.Tile{}
+
+	tiles := w.TileMap.Tiles
+	tw, th := float32(tiles.TileWidth), float32(tiles.TileHeight)
+	for y := int(d.area.Y / th); float32(y)*th < d.area.Y+d.area.Height; y++ {
+		for x := int(d.area.X / tw); float32(x)*tw < d.area.X+d.area.Width; x++ {
+			if tile := tiles.At(x, y); tile != nil {
+				d.tiles[[2]int{x, y}] = tile
+				tiles.Set(x, y, nil)
+			}
+		}
+	}
+	if d.prop != nil {
+		d.prop.Hidden = true
+	}
+}
+
+// closeDoor puts back the tiles the door had when it opened
+func (w *World) closeDoor(d *door) {
+	if !d.open {
+		return
+	}
+	d.open = false
+	for cell, tile := range d.tiles {
+		w.TileMap.Tiles.Set(cell[0], cell[1], tile)
+	}
+	if d.prop != nil {
+		d.prop.Hidden = false
+	}
+}
+
+// NextCutscene returns the name of the next cutscene a trigger has started,
+// false when there are none waiting
+func (w *World) NextCutscene() (string, bool) {
+	if len(w.cutscenes) == 0 {
+		return "", false
+	}
+	name := w.cutscenes[0]
+	w.cutscenes = w.cutscenes[1:]
+	return name, true
+}
+
+// CutsceneLines returns what a cutscene shows, from the level's settings or a
+// cutscene object in its map, a line at a time
+func (w *World) CutsceneLines(name string) []string {
+	if lines, ok := w.Settings.Cutscenes[name]; ok {
+		return lines
+	}
+	for _, object := range w.TileMap.ObjectsOfClass(game_manager.CutsceneClass) {
+		if lines := strings.TrimSpace(object.Properties.String("lines", "")); object.Name == name && lines != "" {
+			return strings.Split(lines, "\n")
+		}
+	}
+	return nil
+}
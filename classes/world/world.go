@@ -0,0 +1,348 @@
+package world
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"log"
+	"math"
+
+	"github.com/grcatterall/go-game/classes/characters"
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/objects/pickups"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// fallMargin is how far below the bottom of the map the player can fall before they are lost
+const fallMargin = 512
+
+// World holds the simulation state of a level. Stepping it never touches the
+// window, so it can run headlessly in tests with drawing layered on top when
+// needed. Message is text a trigger put on screen, for MessageTime more seconds
+type World struct {
+	Level       int
+	Settings    *levels.Level
+	Player      *characters.Player
+	Enemies     []*characters.Enemy
+	Pickups     []*pickups.Pickup
+	Triggers    []*game_manager.Trigger
+	Platforms   []*game_manager.Platform
+	TileMap     *game_manager.TileMap
+	Exits       []rl.Rectangle
+	Checkpoints []*game_manager.Checkpoint
+	Checkpoint  *game_manager.Checkpoint // the last one the player touched, nil until they touch one
+	Message     string
+	MessageTime float32
+	Tick        uint64
+	Seed        int64 // recorded with replays, nothing in the simulation is random
+	Debug       bool
+	waves       map[string][]game_manager.Spawn // enemies waiting for a trigger to spawn them
+	doors       map[string]*door
+	cutscenes   []string // cutscenes triggers started that haven't been shown
+	ended       bool     // a trigger ended the level
+	snapshot    playerSnapshot
+	// every enemy the level places and where each living enemy was placed,
+	// for putting sections back when the player respawns
+	enemyPlacements []game_manager.Spawn
+	enemySpawns     map[*characters.Enemy]game_manager.Spawn
+}
+
+// NewWorld loads a level with its player and enemies. Stepping it uses no
+// randomness, so the same inputs always play out the same way and runs can be
+// replayed exactly from their level and inputs alone
+func NewWorld(level int, seed int64) *World {
+	settings := levelSettings(level)
+	tileMap, spawns := game_manager.LoadLevel(settings)
+	return newWorld(level, settings, tileMap, spawns, seed)
+}
+
+// NewWorldFromMap starts a level on a map that has already been built, like
+// one being edited, placing the entities it holds. The world takes the map
+func NewWorldFromMap(level int, tileMap *game_manager.TileMap, seed int64) *World {
+	settings := levelSettings(level)
+	return newWorld(level, settings, tileMap, game_manager.LevelSpawns(settings, tileMap), seed)
+}
+
+// levelSettings returns a level's settings, the first level's when there is no such level
+func levelSettings(level int) *levels.Level {
+	settings := levels.Default.Level(level)
+	if settings == nil {
+		log.Printf("world: no level %d, loading the first level", level)
+		settings = levels.Default.First()
+	}
+	return settings
+}
+
+func newWorld(level int, settings *levels.Level, tileMap *game_manager.TileMap, spawns []game_manager.Spawn, seed int64) *World {
+	playerSpawn := game_manager.SpawnsOfKind(spawns, game_manager.SpawnPlayer)[0]
+	player := characters.NewPlayer(playerSpawn.Position)
+	player.IsLeft = playerSpawn.FacingLeft
+
+	w := &World{
+		Level:           level,
+		Settings:        settings,
+		Seed:            seed,
+		Player:          player,
+		Platforms:       tileMap.Platforms(),
+		TileMap:         tileMap,
+		Exits:           exitZones(settings, tileMap),
+		Checkpoints:     tileMap.Checkpoints(),
+		enemyPlacements: game_manager.SpawnsOfKind(spawns, game_manager.SpawnEnemy),
+		enemySpawns:     map[*characters.Enemy]game_manager.Spawn{},
+	}
+
+	for _, spawn := range w.enemyPlacements {
+		if spawn.Wave == "" {
+			w.spawnEnemy(spawn)
+		}
+	}
+
+	for _, spawn := range game_manager.SpawnsOfKind(spawns, game_manager.SpawnPickup) {
+		amount := int32(spawn.Properties.Float("amount", 1))
+		w.Pickups = append(w.Pickups, pickups.NewPickup(spawn.Archetype, amount, spawn.Position))
+	}
+
+	w.loadTriggers(spawns)
+
+	player.OnMeleeHit = w.meleeHit
+
+	return w
+}
+
+// spawnEnemy places an enemy going after the player
+func (w *World) spawnEnemy(spawn game_manager.Spawn) {
+	enemy := characters.NewEnemy(spawn.Archetype, spawn.Health, spawn.Position, w.Player)
+	enemy.FacingLeft = spawn.FacingLeft
+	enemy.PatrolRange = spawn.PatrolRange
+	w.Enemies = append(w.Enemies, enemy)
+	w.enemySpawns[enemy] = spawn
+}
+
+// exitZones collects the exit objects in a level's map and the exits in its settings
+func exitZones(settings *levels.Level, tileMap *game_manager.TileMap) []rl.Rectangle {
+	var exits []rl.Rectangle
+	for _, object := range tileMap.ObjectsOfClass("exit") {
+		exits = append(exits, object.Bounds())
+	}
+	for _, zone := range settings.Exits {
+		exits = append(exits, rl.NewRectangle(zone.X, zone.Y, zone.Width, zone.Height))
+	}
+	return exits
+}
+
+// LevelComplete reports whether the player has reached one of the level's
+// exits or a trigger has ended the level
+func (w *World) LevelComplete() bool {
+	if w.ended {
+		return true
+	}
+	body := w.Player.Bounds()
+	for _, exit := range w.Exits {
+		if rl.CheckCollisionRecs(body, exit) {
+			return true
+		}
+	}
+	return false
+}
+
+// meleeDamage is how much health a melee hit takes from an enemy
+const meleeDamage = 1
+
+// meleeHit damages every enemy inside the area the player's melee attack struck
+func (w *World) meleeHit(area rl.Rectangle) {
+	for _, enemy := range w.Enemies {
+		if rl.CheckCollisionRecs(area, enemy.Bounds()) {
+			enemy.TakeDamage(meleeDamage)
+		}
+	}
+}
+
+// removeDeadEnemies unloads and drops enemies that have run out of health
+func (w *World) removeDeadEnemies() {
+	alive := w.Enemies[:0]
+	for _, enemy := range w.Enemies {
+		if enemy.IsDead() {
+			enemy.Unload()
+			delete(w.enemySpawns, enemy)
+			continue
+		}
+		alive = append(alive, enemy)
+	}
+	w.Enemies = alive
+}
+
+// collectPickups hands the player every pickup they are touching
+func (w *World) collectPickups() {
+	for _, pickup := range w.Pickups {
+		if pickup.Collected || !w.Player.Touches(pickup.Bounds()) {
+			continue
+		}
+
+		switch pickup.Kind {
+		case pickups.Health:
+			w.Player.Heal(pickup.Amount)
+		default:
+			log.Printf("world: unknown pickup %q", pickup.Kind)
+		}
+		pickup.Collected = true
+	}
+}
+
+// movePlatforms moves every platform on and carries along the player and the
+// enemies standing on them
+func (w *World) movePlatforms(dt float32) {
+	for _, platform := range w.Platforms {
+		var riders []*characters.Enemy
+		for _, enemy := range w.Enemies {
+			if platform.Supports(enemy.Bounds()) {
+				riders = append(riders, enemy)
+			}
+		}
+
+		platform.Update(dt)
+
+		for _, enemy := range riders {
+			enemy.Move(platform.Delta)
+		}
+		if w.Player.Platform == platform {
+			w.Player.Position = rl.Vector2Add(w.Player.Position, platform.Delta)
+		}
+	}
+}
+
+// Step advances the simulation by dt seconds
+func (w *World) Step(dt float32) {
+	w.movePlatforms(dt)
+
+	w.Player.Update(w.TileMap, dt)
+
+	w.Player.CheckCollisions(w.TileMap, w.Platforms)
+	if w.Player.Platform != nil {
+		w.Player.Platform.StandOn()
+	}
+
+	for _, enemy := range w.Enemies {
+		enemy.Update(dt)
+	}
+
+	w.removeDeadEnemies()
+
+	w.collectPickups()
+
+	w.updateTriggers(dt)
+
+	w.touchCheckpoints()
+	if w.PlayerDead() && w.Checkpoint != nil {
+		w.respawn()
+	}
+
+	w.Tick++
+}
+
+// PlayerDead reports whether the player has run out of health or fallen out of
+// the map. Once they have touched a checkpoint Step respawns them there instead
+func (w *World) PlayerDead() bool {
+	return w.Player.IsDead() || w.PlayerOutOfBounds()
+}
+
+// PlayerOutOfBounds reports whether the player has fallen out of the bottom of the map
+func (w *World) PlayerOutOfBounds() bool {
+	return w.Player.Position.Y > w.TileMap.Height()+fallMargin
+}
+
+// StateHash summarises the player's position and health, enemy health, pickups, platforms and live bullets so
+// two runs of the same inputs can be checked for divergence
+func (w *World) StateHash() uint64 {
+	hash := fnv.New64a()
+	writeFloats := func(values ...float32) {
+		var buf [4]byte
+		for _, value := range values {
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(value))
+			hash.Write(buf[:])
+		}
+	}
+
+	writeFloats(w.Player.Position.X, w.Player.Position.Y, w.Player.Velocity.X, w.Player.Velocity.Y)
+	binary.Write(hash, binary.LittleEndian, w.Player.Health)
+
+	for _, enemy := range w.Enemies {
+		binary.Write(hash, binary.LittleEndian, enemy.Health)
+		writeFloats(enemy.Position.X, enemy.Position.Y)
+	}
+
+	for _, pickup := range w.Pickups {
+		binary.Write(hash, binary.LittleEndian, pickup.Collected)
+	}
+
+	for _, platform := range w.Platforms {
+		writeFloats(platform.Area.X, platform.Area.Y)
+	}
+
+	binary.Write(hash, binary.LittleEndian, int32(len(w.Player.Bullets)))
+	for _, bullet := range w.Player.Bullets {
+		writeFloats(bullet.Position.X, bullet.Position.Y)
+	}
+
+	return hash.Sum64()
+}
+
+// Draw renders the level with its characters between the background and
+// foreground layers, call it inside BeginMode2D. Tiles outside the view are skipped
+func (w *World) Draw(alpha float32, view rl.Rectangle) {
+	w.TileMap.DrawBackground(view)
+
+	for _, platform := range w.Platforms {
+		platform.Draw()
+	}
+
+	for _, pickup := range w.Pickups {
+		pickup.Draw()
+	}
+
+	for _, checkpoint := range w.Checkpoints {
+		checkpoint.Draw(checkpoint == w.Checkpoint)
+	}
+
+	// Draw the player
+	w.Player.Draw(alpha)
+
+	for _, enemy := range w.Enemies {
+		enemy.Draw(alpha)
+	}
+
+	w.TileMap.DrawForeground(view)
+
+	if w.Debug {
+		for _, exit := range w.Exits {
+			rl.DrawRectangleLinesEx(exit, 2, rl.Gold)
+		}
+		for _, trigger := range w.Triggers {
+			rl.DrawRectangleLinesEx(trigger.Area, 2, rl.Purple)
+			rl.DrawText(trigger.Name, int32(trigger.Area.X)+4, int32(trigger.Area.Y)+4, 10, rl.Purple)
+		}
+		for _, d := range w.doors {
+			color := rl.Red
+			if d.open {
+				color = rl.Green
+			}
+			rl.DrawRectangleLinesEx(d.area, 2, color)
+		}
+		for _, platform := range w.Platforms {
+			platform.DrawDebug()
+		}
+		for _, checkpoint := range w.Checkpoints {
+			rl.DrawRectangleLinesEx(checkpoint.Area, 2, rl.SkyBlue)
+		}
+		w.Player.DrawDebug(w.TileMap)
+	}
+}
+
+// Unload releases every texture the world loaded
+func (w *World) Unload() {
+	w.Player.Unload()
+	for _, enemy := range w.Enemies {
+		enemy.Unload()
+	}
+	w.TileMap.Unload()
+}
@@ -0,0 +1,186 @@
+package world
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Levels, sprite manifests and textures are found from the root of the repository
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+const tick = 1.0 / 60
+
+// testLevel is the number the test levels are registered under
+const testLevel = 1
+
+// useLevel makes settings the only level for the rest of a test
+func useLevel(t *testing.T, settings *levels.Level) {
+	t.Helper()
+	previous := levels.Default
+	levels.Default = &levels.Registry{Levels: []*levels.Level{settings}}
+	t.Cleanup(func() { levels.Default = previous })
+}
+
+// floorMap is a map 40 tiles wide and 12 high, with a solid floor along row
+// 10 from column from up to column to
+func floorMap(from, to int) *game_manager.TileMap {
+	level := make([][]int, 12)
+	for y := range level {
+		level[y] = make([]int, 40)
+	}
+	for x := from; x < to; x++ {
+		level[10][x] = 1
+	}
+	return game_manager.LoadGoLevel(level, nil)
+}
+
+// stepUntil steps the world until done reports true, failing after ticks
+func stepUntil(t *testing.T, w *World, ticks int, done func() bool) {
+	t.Helper()
+	for i := 0; i < ticks; i++ {
+		if done() {
+			return
+		}
+		w.Step(tick)
+	}
+	if !done() {
+		t.Fatalf("still waiting after %d ticks, player at %v", ticks, w.Player.Position)
+	}
+}
+
+func TestPlayerLandsOnRegistryLevel(t *testing.T) {
+	registry, err := levels.LoadRegistry(levels.RegistryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := levels.Default
+	levels.Default = registry
+	t.Cleanup(func() { levels.Default = previous })
+
+	for _, settings := range registry.Levels {
+		w := NewWorld(settings.Number, 1)
+		stepUntil(t, w, 180, func() bool { return w.Player.IsGrounded })
+
+		feet := w.Player.Position.Y + w.Player.CurrentAnimation.FrameRec.Height
+		if row := int(feet) / int(w.TileMap.TileHeight); float32(row*int(w.TileMap.TileHeight)) != feet {
+			t.Errorf("level %d: player stands at %v, not on top of a tile", settings.Number, feet)
+		}
+		if w.PlayerDead() || w.LevelComplete() {
+			t.Errorf("level %d: player is dead or done after landing", settings.Number)
+		}
+		w.Unload()
+	}
+}
+
+func TestPlayerLandsOnGround(t *testing.T) {
+	useLevel(t, &levels.Level{Number: testLevel, Spawn: levels.Point{X: 100, Y: 100}})
+	w := NewWorldFromMap(testLevel, floorMap(0, 40), 1)
+	defer w.Unload()
+
+	stepUntil(t, w, 120, func() bool { return w.Player.IsGrounded })
+	for i := 0; i < 60; i++ {
+		w.Step(tick)
+	}
+
+	if feet, want := w.Player.Position.Y+w.Player.CurrentAnimation.FrameRec.Height, float32(10*game_manager.TileSize); feet != want {
+		t.Errorf("feet at %v, want the top of the floor at %v", feet, want)
+	}
+	if !w.Player.IsGrounded || w.Player.Velocity.Y != 0 || w.PlayerOutOfBounds() {
+		t.Errorf("player isn't standing still: grounded %v velocity %v", w.Player.IsGrounded, w.Player.Velocity)
+	}
+}
+
+func TestPlayerOutOfBoundsAfterFall(t *testing.T) {
+	// The floor stops short of where the player spawns
+	useLevel(t, &levels.Level{Number: testLevel, Spawn: levels.Point{X: 600, Y: 100}})
+	w := NewWorldFromMap(testLevel, floorMap(0, 10), 1)
+	defer w.Unload()
+
+	if w.PlayerOutOfBounds() {
+		t.Fatal("player is out of bounds before falling")
+	}
+	stepUntil(t, w, 600, w.PlayerOutOfBounds)
+
+	if !w.PlayerDead() {
+		t.Error("a player out of bounds should count as dead")
+	}
+	if w.Player.Position.Y <= w.TileMap.Height()+fallMargin {
+		t.Errorf("player at %v isn't below the map", w.Player.Position)
+	}
+}
+
+func TestLevelCompleteAtExit(t *testing.T) {
+	useLevel(t, &levels.Level{
+		Number: testLevel,
+		Spawn:  levels.Point{X: 100, Y: 100},
+		Exits:  []levels.Zone{{X: 800, Y: 192, Width: 64, Height: 128}},
+	})
+	w := NewWorldFromMap(testLevel, floorMap(0, 40), 1)
+	defer w.Unload()
+
+	stepUntil(t, w, 120, func() bool { return w.Player.IsGrounded })
+	if w.LevelComplete() {
+		t.Fatal("level is complete before reaching the exit")
+	}
+
+	w.Player.Position = rl.Vector2{X: 780, Y: w.Player.Position.Y}
+	w.Step(tick)
+	if !w.LevelComplete() {
+		t.Errorf("level isn't complete with the player at %v in the exit", w.Player.Bounds())
+	}
+}
+
+func TestRespawnPutsBackWavesWhoseTriggerAlreadyFired(t *testing.T) {
+	// The ambush trigger stands where the player spawns, before the
+	// checkpoint, so resetting the checkpoint's section doesn't reset it. The
+	// later trigger is never reached
+	useLevel(t, &levels.Level{
+		Number: testLevel,
+		Spawn:  levels.Point{X: 100, Y: 100},
+		Enemies: []levels.EnemySpawn{
+			{Character: "Raider_1", Health: 5, X: 900, Y: 512, Wave: "ambush"},
+			{Character: "Raider_1", Health: 5, X: 1000, Y: 512, Wave: "later"},
+		},
+		Triggers: []levels.Trigger{
+			{Name: "ambush", Zone: levels.Zone{X: 0, Y: 0, Width: 300, Height: 640}, Actions: []string{"spawn ambush"}},
+			{Name: "later", Zone: levels.Zone{X: 1200, Y: 0, Width: 64, Height: 640}, Actions: []string{"spawn later"}},
+		},
+	})
+	w := NewWorldFromMap(testLevel, floorMap(0, 40), 1)
+	defer w.Unload()
+	checkpoint := game_manager.NewCheckpoint(rl.NewRectangle(400, 608, 32, 32))
+	checkpoint.ResetEnemies = true
+	w.Checkpoints = []*game_manager.Checkpoint{checkpoint}
+
+	stepUntil(t, w, 120, func() bool { return len(w.Enemies) == 1 })
+	w.Player.Position = checkpoint.Spawn
+	w.Step(tick)
+	if w.Checkpoint != checkpoint {
+		t.Fatalf("player at %v didn't touch the checkpoint", w.Player.Position)
+	}
+
+	w.Enemies[0].TakeDamage(w.Enemies[0].Health)
+	w.Step(tick)
+	w.Player.Health = 0
+	w.Step(tick)
+
+	if w.PlayerDead() {
+		t.Fatal("player didn't respawn")
+	}
+	if len(w.Enemies) != 1 || w.Enemies[0].Position.X != 900 {
+		t.Fatalf("want the ambush back at x 900 after respawning, got %d enemies", len(w.Enemies))
+	}
+	if len(w.waves["later"]) != 1 {
+		t.Errorf("the later wave isn't waiting for its trigger: %v", w.waves)
+	}
+}
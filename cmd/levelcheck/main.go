@@ -0,0 +1,265 @@
+// Command levelcheck loads every level in the registry, or the ones numbered
+// on the command line, and reports problems without opening a window:
+//
+//   - Go level data with ragged rows or tile IDs that have no texture
+//   - Tiled maps that fail to load, such as tiles missing from their tilesets
+//   - levels without an exit
+//   - player spawns with no ground below them
+//   - enemies, pickups and exits the player can't get to, counting on platforms
+//     to stand anywhere along their paths
+//   - enemies placed inside solid tiles
+//   - checkpoints the player can't get to or respawn on
+//   - triggers whose actions don't parse or name waves, doors or cutscenes
+//     the level doesn't have
+//
+// It exits with status 1 when it finds anything, for CI.
+//
+//	go run ./cmd/levelcheck
+//	go run ./cmd/levelcheck -levels assets/levels/levels.json 2
+//	go run ./cmd/levelcheck -endless 50 -seed 7
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/grcatterall/go-game/classes/characters"
+	"github.com/grcatterall/go-game/classes/game_manager"
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// settleTicks is how long the player is given to land after spawning
+const settleTicks = 180
+
+func main() {
+	registryPath := flag.String("levels", levels.RegistryFile, "level registry to check")
+	endless := flag.Int("endless", 0, "check this many generated levels instead of the registry")
+	seed := flag.Int64("seed", 1, "seed for -endless levels")
+	flag.Parse()
+
+	registry, err := loadRegistry(*registryPath, *endless, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	levels.Default = registry
+	rl.SetTraceLogLevel(rl.LogError)
+
+	toCheck := registry.Levels
+	if flag.NArg() > 0 {
+		toCheck = nil
+		for _, arg := range flag.Args() {
+			number, err := strconv.Atoi(arg)
+			if err != nil || registry.Level(number) == nil {
+				fmt.Fprintf(os.Stderr, "no level %q in %s\n", arg, *registryPath)
+				os.Exit(1)
+			}
+			toCheck = append(toCheck, registry.Level(number))
+		}
+	}
+
+	failed := false
+	for _, settings := range toCheck {
+		problems := checkLevel(settings)
+		for _, problem := range problems {
+			fmt.Printf("level %d (%s): %s\n", settings.Number, settings.Name, problem)
+		}
+		if len(problems) == 0 {
+			fmt.Printf("level %d (%s): ok\n", settings.Number, settings.Name)
+		}
+		failed = failed || len(problems) > 0
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// loadRegistry reads a level registry, or generates count levels when count is set
+func loadRegistry(path string, count int, seed int64) (*levels.Registry, error) {
+	if count <= 0 {
+		return levels.LoadRegistry(path)
+	}
+
+	options := game_manager.DefaultGeneratorOptions
+	options.Seed = seed
+	options.Jump = characters.PlayerJump()
+	registry := game_manager.EndlessRegistry(options)
+	for len(registry.Levels) < count {
+		registry.Next(registry.Levels[len(registry.Levels)-1].Number)
+	}
+	return registry, nil
+}
+
+// checkLevel returns everything wrong with a level
+func checkLevel(settings *levels.Level) []string {
+	var problems []string
+
+	// Levels whose map fails to load are played from their Go data, so it is
+	// checked even when there is a map
+	if level := settings.GoData(); level != nil {
+		problems = append(problems, checkGoLevel(level)...)
+	}
+
+	var tileMap *game_manager.TileMap
+	if path := settings.MapPath(); path != "" {
+		var err error
+		if tileMap, err = game_manager.LoadTiledMap(path); err != nil {
+			return append(problems, err.Error())
+		}
+	} else if level := settings.GoData(); level != nil {
+		tileMap = game_manager.LoadGoLevel(level, nil)
+	} else {
+		return append(problems, "has neither a map nor Go level data")
+	}
+
+	spawns := game_manager.LevelSpawns(settings, tileMap)
+	w := world.NewWorldFromMap(settings.Number, tileMap, 1)
+	defer w.Unload()
+	problems = append(problems, checkTriggers(w, spawns)...)
+	return append(problems, checkWorld(w)...)
+}
+
+// checkTriggers looks for trigger actions that don't parse or name something
+// the level doesn't have
+func checkTriggers(w *world.World, spawns []game_manager.Spawn) []string {
+	var problems []string
+
+	waves := map[string]bool{}
+	for _, spawn := range game_manager.SpawnsOfKind(spawns, game_manager.SpawnEnemy) {
+		waves[spawn.Wave] = true
+	}
+	doors := map[string]bool{}
+	for _, object := range w.TileMap.ObjectsOfClass(game_manager.DoorClass) {
+		doors[object.Name] = true
+	}
+
+	for _, spawn := range game_manager.SpawnsOfKind(spawns, game_manager.SpawnTrigger) {
+		trigger, err := game_manager.NewTrigger(spawn)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+
+		for _, action := range trigger.Actions {
+			missing := ""
+			switch action.Verb {
+			case game_manager.ActionSpawn:
+				if !waves[action.Target] {
+					missing = "wave"
+				}
+			case game_manager.ActionOpen, game_manager.ActionClose:
+				if !doors[action.Target] {
+					missing = "door"
+				}
+			case game_manager.ActionCutscene:
+				if len(w.CutsceneLines(action.Target)) == 0 {
+					missing = "cutscene"
+				}
+			}
+			if missing != "" {
+				problems = append(problems, fmt.Sprintf("trigger %q: there's no %s %q", trigger.Name, missing, action.Target))
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkGoLevel looks for rows of a different length to the rest and tile IDs with no texture
+func checkGoLevel(level [][]int) []string {
+	var problems []string
+
+	// Most rows are taken to be the right length
+	widths := map[int]int{}
+	width := 0
+	for _, row := range level {
+		widths[len(row)]++
+		if widths[len(row)] > widths[width] {
+			width = len(row)
+		}
+	}
+	for y, row := range level {
+		if len(row) != width {
+			problems = append(problems, fmt.Sprintf("go data row %d has %d tiles, the other rows have %d", y, len(row), width))
+		}
+	}
+
+	unknown := map[int]bool{}
+	for y, row := range level {
+		for x, id := range row {
+			if _, ok := game_manager.TileTexturePaths[id]; ok || id == 0 || id == levels.Ground || unknown[id] {
+				continue
+			}
+			unknown[id] = true
+			problems = append(problems, fmt.Sprintf("go data tile ID %d at %d,%d has no texture", id, x, y))
+		}
+	}
+
+	return problems
+}
+
+// checkWorld lets the player land, then checks where everything else is
+// against where the player can get to from there
+func checkWorld(w *world.World) []string {
+	var problems []string
+
+	if len(w.Exits) == 0 {
+		problems = append(problems, "has no exit")
+	}
+
+	grid := newReachGrid(w.TileMap, w.Platforms, w.Player)
+
+	// Enemies move once the world steps, so look at where they were placed first
+	enemies := make([]rl.Rectangle, len(w.Enemies))
+	for i, enemy := range w.Enemies {
+		enemies[i] = enemy.Bounds()
+	}
+
+	spawn := w.Player.Position
+	for i := 0; i < settleTicks && !w.PlayerOutOfBounds(); i++ {
+		w.Step(1.0 / 60)
+	}
+	body := w.Player.Bounds()
+	start, landed := grid.landing(body.X+body.Width/2, body.Y+body.Height)
+	if w.PlayerOutOfBounds() || !landed {
+		return append(problems, fmt.Sprintf("player spawn at %.0f,%.0f has no ground below it", spawn.X, spawn.Y))
+	}
+	reachable := grid.reachableFrom(start)
+
+	for _, bounds := range enemies {
+		if grid.overlapsSolid(bounds) {
+			problems = append(problems, fmt.Sprintf("enemy at %.0f,%.0f is inside solid tiles", bounds.X, bounds.Y))
+			continue
+		}
+		if cell, ok := grid.landing(bounds.X+bounds.Width/2, bounds.Y+bounds.Height); !ok || !reachable[cell] {
+			problems = append(problems, fmt.Sprintf("enemy at %.0f,%.0f can't be reached", bounds.X, bounds.Y))
+		}
+	}
+
+	for _, pickup := range w.Pickups {
+		if cell, ok := grid.landing(pickup.Position.X, pickup.Position.Y); !ok || !reachable[cell] {
+			problems = append(problems, fmt.Sprintf("%s pickup at %.0f,%.0f can't be reached", pickup.Kind, pickup.Position.X, pickup.Position.Y))
+		}
+	}
+
+	for _, checkpoint := range w.Checkpoints {
+		spawn := checkpoint.Spawn
+		if cell, ok := grid.landing(checkpoint.Area.X+checkpoint.Area.Width/2, checkpoint.Area.Y+checkpoint.Area.Height); !ok || !reachable[cell] {
+			problems = append(problems, fmt.Sprintf("checkpoint at %.0f,%.0f can't be reached", spawn.X, spawn.Y))
+		}
+	}
+
+	for _, exit := range w.Exits {
+		if !grid.touches(reachable, exit) {
+			problems = append(problems, fmt.Sprintf("exit at %.0f,%.0f can't be reached", exit.X, exit.Y))
+		}
+	}
+
+	return problems
+}
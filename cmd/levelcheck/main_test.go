@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grcatterall/go-game/classes/game_manager/levels"
+)
+
+func TestCheckGoLevelReportsRaggedRows(t *testing.T) {
+	level := [][]int{
+		{levels.Ground, 0, 0, levels.Ground},
+		{levels.Ground, 0, 0, levels.Ground},
+		{levels.Ground, levels.Ground, levels.Ground, levels.Ground, levels.Ground, levels.Ground},
+	}
+
+	problems := checkGoLevel(level)
+	if len(problems) != 1 || !strings.Contains(problems[0], "row 2 has 6 tiles, the other rows have 4") {
+		t.Errorf("got problems %q, want the ragged row 2", problems)
+	}
+}
+
+func TestCheckGoLevelReportsUnknownTiles(t *testing.T) {
+	problems := checkGoLevel([][]int{{levels.Ground, -7, -7}})
+	if len(problems) != 1 || !strings.Contains(problems[0], "tile ID -7 at 1,0") {
+		t.Errorf("got problems %q, want the unknown tile once", problems)
+	}
+}
+
+func TestGoLevelsAreClean(t *testing.T) {
+	for number := 1; levels.GetLevel(number) != nil; number++ {
+		if problems := checkGoLevel(levels.GetLevel(number)); len(problems) > 0 {
+			t.Errorf("level %d: %q", number, problems)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"math"
+
+	"github.com/grcatterall/go-game/classes/characters"
+	"github.com/grcatterall/go-game/classes/game_manager"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// cell is a position in the collision grid
+type cell struct {
+	x, y int
+}
+
+// reachGrid works out where the player can stand and which of those places
+// can be jumped or dropped between, from the player's physics. Platforms count
+// as ground in every cell their top passes through
+type reachGrid struct {
+	tiles     *game_manager.TileGrid
+	platforms map[cell]bool
+	tw, th    float32
+	clearance int // cells of headroom the player's body needs
+	rise      int // cells the player can jump up
+	speed     float32
+	gravity   float32
+	jumpSpeed float32
+}
+
+func newReachGrid(tileMap *game_manager.TileMap, platforms []*game_manager.Platform, player *characters.Player) *reachGrid {
+	tw, th := float32(tileMap.TileWidth), float32(tileMap.TileHeight)
+	body := player.Bounds()
+	height := player.JumpSpeed * player.JumpSpeed / (2 * player.Gravity)
+	return &reachGrid{
+		tiles:     tileMap.Tiles,
+		platforms: platformCells(platforms, tw, th),
+		tw:        tw,
+		th:        th,
+		clearance: int(math.Ceil(float64(body.Height / th))),
+		rise:      int(height / th),
+		speed:     player.Jump().Speed,
+		gravity:   player.Gravity,
+		jumpSpeed: player.JumpSpeed,
+	}
+}
+
+// solid reports whether a cell blocks the player's body. The sides of the map
+// are walls, above and below it is open
+func (g *reachGrid) solid(x, y int) bool {
+	if x < 0 || x >= g.tiles.Width {
+		return true
+	}
+	tile := g.tiles.At(x, y)
+	return tile != nil && tile.Definition.Shape == game_manager.ShapeSolid
+}
+
+// free reports whether the player's body fits with its feet at the top of row y
+func (g *reachGrid) free(x, y int) bool {
+	for row := y - g.clearance; row < y; row++ {
+		if g.solid(x, row) {
+			return false
+		}
+	}
+	return true
+}
+
+// standable reports whether the player can stand on a cell without getting hurt
+func (g *reachGrid) standable(c cell) bool {
+	tile := g.tiles.At(c.x, c.y)
+	ground := tile != nil && tile.Definition.Shape != game_manager.ShapeNone && tile.Definition.Damage == 0
+	return (ground || g.platforms[c]) && g.free(c.x, c.y)
+}
+
+// platformCells returns the cells the tops of platforms pass through, stepping
+// along their paths a tile at a time
+func platformCells(platforms []*game_manager.Platform, tw, th float32) map[cell]bool {
+	cells := map[cell]bool{}
+	mark := func(area rl.Rectangle) {
+		y := int(math.Floor(float64(area.Y / th)))
+		for x := int(area.X / tw); float32(x)*tw < area.X+area.Width; x++ {
+			cells[cell{x, y}] = true
+		}
+	}
+
+	for _, platform := range platforms {
+		area := platform.Area
+		mark(area)
+		for i := 1; i < len(platform.Path); i++ {
+			from, to := platform.Path[i-1], platform.Path[i]
+			steps := int(rl.Vector2Distance(from, to)/min(tw, th)) + 1
+			for step := 0; step <= steps; step++ {
+				position := rl.Vector2Lerp(from, to, float32(step)/float32(steps))
+				area.X, area.Y = position.X, position.Y
+				mark(area)
+			}
+		}
+	}
+	return cells
+}
+
+// headroom returns how many cells the player can jump up from a cell before hitting a ceiling
+func (g *reachGrid) headroom(c cell) int {
+	rise := 0
+	for rise < g.rise && !g.solid(c.x, c.y-g.clearance-rise-1) {
+		rise++
+	}
+	return rise
+}
+
+// landing returns the first tile at or below a point, where something dropped
+// there would come to rest. It fails for points over a gap or a hurting tile
+func (g *reachGrid) landing(x, y float32) (cell, bool) {
+	cx := int(math.Floor(float64(x / g.tw)))
+	if cx < 0 || cx >= g.tiles.Width {
+		return cell{}, false
+	}
+	for cy := max(int(math.Floor(float64((y-1)/g.th))), 0); cy < g.tiles.Height; cy++ {
+		tile := g.tiles.At(cx, cy)
+		if tile == nil || tile.Definition.Shape == game_manager.ShapeNone {
+			continue
+		}
+		return cell{cx, cy}, tile.Definition.Damage == 0
+	}
+	return cell{}, false
+}
+
+// reachableFrom returns every cell the player can stand on starting from one
+func (g *reachGrid) reachableFrom(start cell) map[cell]bool {
+	reachable := map[cell]bool{start: true}
+	queue := []cell{start}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+
+		rise := g.headroom(from)
+		for y := from.y - rise; y < g.tiles.Height; y++ {
+			span := g.span(y - from.y + rise)
+			for x := from.x - span; x <= from.x+span; x++ {
+				to := cell{x, y}
+				if reachable[to] || !g.standable(to) || !g.passable(from, to, rise) {
+					continue
+				}
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reachable
+}
+
+// span returns how many cells across a jump carries the player, landing drop
+// cells below the top of the jump
+func (g *reachGrid) span(drop int) int {
+	up := g.jumpSpeed / g.gravity
+	fall := float32(drop)*g.th + g.jumpSpeed*g.jumpSpeed/(2*g.gravity)
+	down := float32(math.Sqrt(float64(2 * fall / g.gravity)))
+	return 1 + int(g.speed*(up+down)/g.tw)
+}
+
+// passable reports whether every column between two cells has room for the
+// player's body somewhere between the top of the jump and the lower of the two
+func (g *reachGrid) passable(from, to cell, rise int) bool {
+	step := 1
+	if to.x < from.x {
+		step = -1
+	}
+	for x := from.x + step; x != to.x; x += step {
+		room := false
+		for y := from.y - rise; y <= max(from.y, to.y) && !room; y++ {
+			room = g.free(x, y)
+		}
+		if !room {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsSolid reports whether an area overlaps any solid tile
+func (g *reachGrid) overlapsSolid(area rl.Rectangle) bool {
+	left, top := int(area.X/g.tw), int(area.Y/g.th)
+	right := int(math.Ceil(float64((area.X+area.Width)/g.tw))) - 1
+	bottom := int(math.Ceil(float64((area.Y+area.Height)/g.th))) - 1
+	for y := max(top, 0); y <= min(bottom, g.tiles.Height-1); y++ {
+		for x := max(left, 0); x <= min(right, g.tiles.Width-1); x++ {
+			if g.solid(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// touches reports whether the player can get into an area, standing or
+// jumping from any reachable cell
+func (g *reachGrid) touches(reachable map[cell]bool, area rl.Rectangle) bool {
+	for c := range reachable {
+		top := c.y - g.clearance - g.headroom(c)
+		column := rl.NewRectangle(float32(c.x)*g.tw, float32(top)*g.th, g.tw, float32(c.y-top)*g.th)
+		if rl.CheckCollisionRecs(column, area) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+// Command tilebench compares drawing a very wide level the way maps used to be
+// handled, a *Tile per cell drawn every frame, with the chunked TileGrid
+// drawing only what the camera sees, baked into textures or not.
+//
+//	go run ./cmd/tilebench -screens 300
+//
+// It opens a hidden window, drawing needs a graphics context. Standard
+// benchmark flags such as -test.benchtime work too. Building and culling the
+// grid need no window and are benchmarked with go test:
+//
+//	go test -run - -bench TileGrid ./classes/game_manager
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/grcatterall/go-game/classes/game_manager"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	screenWidth  = 960
+	screenHeight = 512
+	tilesetPath  = "assets/world/1 Tiles/Tileset.png"
+)
+
+// perTile is how a tile used to be stored, one per cell with its own position
+type perTile struct {
+	Texture  rl.Texture2D
+	Source   rl.Rectangle
+	Position rl.Vector2
+}
+
+func main() {
+	testing.Init()
+	screens := flag.Int("screens", 300, "how many screens wide the level is")
+	seed := flag.Int64("seed", 1, "seed for the level's layout")
+	flag.Parse()
+
+	rl.SetConfigFlags(rl.FlagWindowHidden)
+	rl.SetTraceLogLevel(rl.LogWarning)
+	rl.InitWindow(screenWidth, screenHeight, "tilebench")
+	defer rl.CloseWindow()
+
+	texture := rl.LoadTexture(tilesetPath)
+	if texture.Width == 0 {
+		log.Fatalf("could not load %s, run from the repository root", tilesetPath)
+	}
+	defer rl.UnloadTexture(texture)
+
+	cells := levelCells(*screens*screenWidth/game_manager.TileSize, screenHeight/game_manager.TileSize, *seed)
+	kinds := tileKinds(texture)
+	fmt.Printf("level %dx%d tiles, %d placed\n\n", len(cells[0]), len(cells), countTiles(cells))
+
+	perTileMap := buildPerTile(cells, kinds)
+	grid := buildGrid(cells, kinds)
+	defer grid.Unload()
+
+	width := float32(len(cells[0]) * game_manager.TileSize)
+	report("draw/per-tile", drawFrames(width, func(rl.Rectangle) {
+		for _, row := range perTileMap {
+			for _, tile := range row {
+				if tile != nil {
+					rl.DrawTextureRec(tile.Texture, tile.Source, tile.Position, rl.White)
+				}
+			}
+		}
+	}))
+	report("draw/culled", drawFrames(width, func(view rl.Rectangle) {
+		grid.Draw(view, rl.White)
+	}))
+	report("draw/baked", drawFrames(width, func(view rl.Rectangle) {
+		grid.Draw(view, rl.White)
+	}, grid))
+}
+
+// report runs a benchmark and prints its time and allocations per operation
+func report(name string, benchmark func(b *testing.B)) {
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		benchmark(b)
+	})
+	fmt.Printf("%-16s %s %s\n", name, result.String(), result.MemString())
+}
+
+// drawFrames benchmarks whole frames with the camera scrolling along the
+// level, baking the grids in view first when there are any
+func drawFrames(width float32, draw func(view rl.Rectangle), baked ...*game_manager.TileGrid) func(b *testing.B) {
+	return func(b *testing.B) {
+		camera := rl.NewCamera2D(rl.Vector2{}, rl.Vector2{}, 0, 1)
+		for i := 0; i < b.N; i++ {
+			camera.Target.X = float32(math.Mod(float64(i*8), float64(width)))
+			view := game_manager.CameraView(camera)
+			for _, grid := range baked {
+				grid.Bake(view)
+			}
+
+			rl.BeginDrawing()
+			rl.ClearBackground(rl.Black)
+			rl.BeginMode2D(camera)
+			draw(view)
+			rl.EndMode2D()
+			rl.EndDrawing()
+		}
+	}
+}
+
+// levelCells lays out a level of tileset IDs: solid ground with gaps and
+// floating platforms, 0 for empty cells
+func levelCells(width, height int, seed int64) [][]int {
+	random := rand.New(rand.NewSource(seed))
+	cells := make([][]int, height)
+	for y := range cells {
+		cells[y] = make([]int, width)
+	}
+
+	for x := 0; x < width; x++ {
+		if random.Intn(12) == 0 {
+			continue
+		}
+		for y := height - 3; y < height; y++ {
+			cells[y][x] = 11
+		}
+		if random.Intn(4) == 0 {
+			cells[height-7-random.Intn(4)][x] = 24
+		}
+	}
+	return cells
+}
+
+// tileKinds cuts the tileset into one shared tile per ID, as many as fit whole
+// in its rows and columns
+func tileKinds(texture rl.Texture2D) map[int]*game_manager.Tile {
+	kinds := map[int]*game_manager.Tile{}
+	columns := int(texture.Width) / game_manager.TileSize
+	rows := int(texture.Height) / game_manager.TileSize
+	for id := 1; id <= columns*rows; id++ {
+		kinds[id] = &game_manager.Tile{
+			Texture: texture,
+			Source: rl.NewRectangle(
+				float32((id-1)%columns*game_manager.TileSize),
+				float32((id-1)/columns*game_manager.TileSize),
+				game_manager.TileSize,
+				game_manager.TileSize,
+			),
+			Definition: game_manager.DefaultTileDefinition,
+		}
+	}
+	return kinds
+}
+
+func buildPerTile(cells [][]int, kinds map[int]*game_manager.Tile) [][]*perTile {
+	tiles := make([][]*perTile, len(cells))
+	for y, row := range cells {
+		tiles[y] = make([]*perTile, len(row))
+		for x, id := range row {
+			if id != 0 {
+				tiles[y][x] = &perTile{
+					Texture:  kinds[id].Texture,
+					Source:   kinds[id].Source,
+					Position: rl.Vector2{X: float32(x * game_manager.TileSize), Y: float32(y * game_manager.TileSize)},
+				}
+			}
+		}
+	}
+	return tiles
+}
+
+func buildGrid(cells [][]int, kinds map[int]*game_manager.Tile) *game_manager.TileGrid {
+	grid := game_manager.NewTileGrid(len(cells[0]), len(cells), game_manager.TileSize, game_manager.TileSize)
+	for y, row := range cells {
+		for x, id := range row {
+			if id != 0 {
+				grid.Set(x, y, kinds[id])
+			}
+		}
+	}
+	return grid
+}
+
+func countTiles(cells [][]int) int {
+	count := 0
+	for _, row := range cells {
+		for _, id := range row {
+			if id != 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -1,73 +1,100 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/grcatterall/go-game/classes/assets"
 	"github.com/grcatterall/go-game/classes/characters"
 	"github.com/grcatterall/go-game/classes/game_manager"
 	"github.com/grcatterall/go-game/classes/game_manager/levels"
+	"github.com/grcatterall/go-game/classes/helpers"
+	"github.com/grcatterall/go-game/classes/input"
+	"github.com/grcatterall/go-game/classes/replay"
+	"github.com/grcatterall/go-game/classes/scenes"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// tickRate is the number of fixed simulation steps per second
+const tickRate = 60
+
+// bindingsFile holds the player's input bindings, written with the defaults on first run
+const bindingsFile = "config/input.json"
+
 func main() {
+	recordPath := flag.String("record", "", "record gameplay input to this file, a file per level with its number before the extension")
+	replayPath := flag.String("replay", "", "play back a recording instead of starting the game, endless runs on the levels they were generated with")
+	bakeTiles := flag.Bool("bake-tiles", false, "draw the level's tiles into a texture per chunk")
+	debug := flag.Bool("debug", false, "outline exits, triggers, platforms and hitboxes, f3 toggles it in play")
+	endless := flag.Bool("endless", false, "play generated levels one after another")
+	seed := flag.Int64("seed", 0, "seed for -endless levels, random when 0")
+	flag.Parse()
+
+	var recording *replay.Recording
+	if *replayPath != "" {
+		var err error
+		if recording, err = replay.Load(*replayPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Initialize the window
 	screenWidth := int32(960)
 	screenHeight := int32(512)
 	rl.InitWindow(screenWidth, screenHeight, "raylib [core] example - sprite animation")
 
-	// Create a new player
-	player := characters.NewPlayer(rl.Vector2{X: float32(screenWidth)/4 - 128, Y: float32(screenHeight)/2 - 30}, 0.2)
-
-	enemy := characters.NewEnemy(
-		"Raider_1",
-		5,
-		rl.Vector2{X: float32(screenWidth) - 128, Y: float32(screenHeight)/2 - 64},
-		0.2,
-		128,
-		128,
-		player,
-	)
-
-	var tileTextures = map[int]rl.Texture2D{
-		1:  game_manager.LoadTile("assets/world/1 Tiles/Tile_01.png"),
-		2:  game_manager.LoadTile("assets/world/1 Tiles/Tile_02.png"),
-		3:  game_manager.LoadTile("assets/world/1 Tiles/Tile_40.png"),
-		4:  game_manager.LoadTile("assets/world/1 Tiles/Tile_39.png"),
-		5:  game_manager.LoadTile("assets/world/1 Tiles/Tile_42.png"),
-		6:  game_manager.LoadTile("assets/world/1 Tiles/Tile_43.png"),
-		7:  game_manager.LoadTile("assets/world/1 Tiles/Tile_37.png"),
-		8:  game_manager.LoadTile("assets/world/1 Tiles/Tile_38.png"),
-		9:  game_manager.LoadTile("assets/world/1 Tiles/Tile_31.png"),
-		10: game_manager.LoadTile("assets/world/1 Tiles/Tile_30.png"),
-		11: game_manager.LoadTile("assets/world/1 Tiles/Tile_29.png"),
-		12: game_manager.LoadTile("assets/world/1 Tiles/Tile_28.png"),
+	rl.InitAudioDevice()
+
+	loadBindings()
+	switch {
+	case recording != nil && recording.Endless:
+		// Replays play the levels they were recorded on, whatever the flags say
+		loadEndlessLevels(recording.EndlessSeed)
+		generateLevels(recording.Level)
+	case recording == nil && *endless:
+		scenes.Endless = true
+		scenes.EndlessSeed = loadEndlessLevels(*seed)
+	default:
+		loadLevels()
 	}
 
-	tileMap := game_manager.LoadLevel(levels.GetLevel(1), tileTextures)
-
-	// Load parallax background layers
-	layerFiles := []string{
-		"assets/world/2 Background/Day/1.png",
-		"assets/world/2 Background/Day/2.png",
-		"assets/world/2 Background/Day/3.png",
-		"assets/world/2 Background/Day/4.png",
-	}
-	speeds := []float32{
-		player.Speed,
-		0.2,
-		0.4,
-		0.8,
+	input.Default.OnGamepadChange = func(connected bool, name string) {
+		if connected {
+			log.Printf("gamepad connected: %s", name)
+		} else {
+			log.Printf("gamepad disconnected")
+		}
 	}
 
-	parallaxBackground := game_manager.NewParallaxBackground(layerFiles, speeds)
+	scenes.TickRate = tickRate
+	scenes.RecordPath = *recordPath
+	scenes.BakeTiles = *bakeTiles
+	scenes.ShowDebug = *debug
 
-	camera := rl.NewCamera2D(player.Position, rl.Vector2{X: 0, Y: 0}, 0, 1)
+	var firstScene game_manager.Scene = scenes.NewTitleScene()
+	if recording != nil {
+		firstScene = scenes.NewReplayScene(recording)
+	}
+
+	sceneManager := game_manager.NewSceneManager(firstScene)
 
-	// Set the target frames per second
+	// Set the target frames per second, the simulation runs at tickRate regardless
 	rl.SetTargetFPS(60)
 
-	for !rl.WindowShouldClose() {
+	timestep := helpers.NewFixedTimestep(tickRate)
 
-		camera.Target = player.Position
+	for !rl.WindowShouldClose() && !sceneManager.ShouldQuit() {
+		ticks := timestep.Advance(rl.GetFrameTime())
+
+		// Update in fixed steps, however many fit in the time since the last frame
+		for i := 0; i < ticks; i++ {
+			input.Default.Poll()
+			sceneManager.Update(timestep.Delta())
+		}
 
 		// Start drawing
 		rl.BeginDrawing()
@@ -75,37 +102,76 @@ func main() {
 		// Clear the background
 		rl.ClearBackground(rl.RayWhite)
 
-		rl.BeginMode2D(camera)
-
-		parallaxBackground.Update(player.Position.X)
-		parallaxBackground.Draw()
-		rl.DrawText("< a d > - shift sprint - left click shoot - right click melee", 100, 30, 20, rl.Black)
+		sceneManager.Draw(timestep.Alpha())
 
-		// Update
-		player.Update(tileMap)
+		// End drawing
+		rl.EndDrawing()
+	}
 
-		player.CheckCollisions(tileMap, camera)
+	// Unload every scene still on the stack
+	sceneManager.Unload()
 
-		enemy.Update()
+	// Report and free any textures nobody released
+	assets.Default.Shutdown()
 
-		tileMap.Draw()
+	// Close the audio device and window
+	rl.CloseAudioDevice()
+	rl.CloseWindow()
+}
 
-		// Draw the player
-		player.Draw()
+// loadLevels replaces the built in level list with the registry on disk
+func loadLevels() {
+	registry, err := levels.LoadRegistry(levels.RegistryFile)
+	if err != nil {
+		log.Printf("using built in levels: %v", err)
+		return
+	}
+	levels.Default = registry
+}
 
-		enemy.Draw()
+// loadEndlessLevels replaces the level list with levels generated from a seed,
+// returning the seed used
+func loadEndlessLevels(seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("endless seed %d", seed)
 
-		rl.EndMode2D()
+	options := game_manager.DefaultGeneratorOptions
+	options.Seed = seed
+	options.Jump = characters.PlayerJump()
+	levels.Default = game_manager.EndlessRegistry(options)
+	return seed
+}
 
-		// End drawing
-		rl.EndDrawing()
+// generateLevels has the endless levels generated up to a level number, so a
+// replay can start partway through a run
+func generateLevels(number int) {
+	for levels.Default.Level(number) == nil {
+		last := levels.Default.Levels[len(levels.Default.Levels)-1]
+		if last.Number >= number || levels.Default.Next(last.Number) == nil {
+			return
+		}
 	}
+}
 
-	// Unload player texture
-	player.Unload()
+// loadBindings applies saved input bindings, saving the defaults if there are none yet
+func loadBindings() {
+	saved, err := input.LoadBindings(bindingsFile)
+	if err == nil {
+		// Actions missing from an older config keep their default bindings
+		for action, bindings := range saved {
+			input.Default.Bindings[action] = bindings
+		}
+		return
+	}
 
-	enemy.Unload()
+	if !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("using default input bindings: %v", err)
+		return
+	}
 
-	// Close the window
-	rl.CloseWindow()
+	if err := input.SaveBindings(bindingsFile, input.Default.Bindings); err != nil {
+		log.Printf("could not save input bindings: %v", err)
+	}
 }